@@ -3,6 +3,13 @@
 // @description     Демонстрационный сервис: получение информации о заказе.
 // @BasePath        /
 // @schemes         http
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT: "Bearer <token>"
 package main
 
 import (
@@ -13,13 +13,29 @@ import (
 	"strings"
 	"time"
 
+	"github.com/RozmiDan/wb_tech_testtask/internal/codec"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
 	"github.com/segmentio/kafka-go"
 )
 
+var contentTypes = map[string]string{
+	"json":     codec.ContentTypeJSON,
+	"protobuf": codec.ContentTypeProtobuf,
+	"avro":     codec.ContentTypeAvro,
+}
+
 type minimalOrder struct {
 	OrderUID string `json:"order_uid"`
 }
 
+// envelope — версионированный конверт, который понимает консьюмер сервиса
+type envelope struct {
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	ProducedAt    time.Time       `json:"produced_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
 func main() {
 	var (
 		brokers  = flag.String("brokers", "kafka:9092", "comma-separated list of brokers")
@@ -31,9 +47,15 @@ func main() {
 		retries  = flag.Int("retries", 3, "retries per message")
 		shuffle  = flag.Bool("shuffle", true, "shuffle files order")
 		repeat   = flag.Bool("repeat", false, "repeat endlessly over the directory")
+		version  = flag.Int("envelope", 0, "wrap files into an envelope with this schema_version (0 = send as is), json only")
+		format   = flag.String("format", "json", "payload format: json, protobuf or avro")
 	)
 	flag.Parse()
 
+	contentType, ok := contentTypes[*format]
+	if !ok {
+		must(fmt.Errorf("unknown format %q", *format))
+	}
 	if *maxDelay < *minDelay {
 		*maxDelay = *minDelay
 	}
@@ -73,7 +95,33 @@ func main() {
 				fmt.Fprintf(os.Stderr, "invalid json or empty order_uid in %s: %v\n", path, err)
 				continue
 			}
-			msg := kafka.Message{Key: []byte(o.OrderUID), Value: payload}
+			if contentType != codec.ContentTypeJSON {
+				order := &entity.OrderInfo{}
+				if err := json.Unmarshal(payload, order); err != nil {
+					fmt.Fprintf(os.Stderr, "parse %s: %v\n", path, err)
+					continue
+				}
+				if payload, err = codec.Encode(contentType, order); err != nil {
+					fmt.Fprintf(os.Stderr, "encode %s as %s: %v\n", path, *format, err)
+					continue
+				}
+			} else if *version > 0 {
+				payload, err = json.Marshal(envelope{
+					Type:          "order.created",
+					SchemaVersion: *version,
+					ProducedAt:    time.Now().UTC(),
+					Payload:       payload,
+				})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "wrap %s: %v\n", path, err)
+					continue
+				}
+			}
+			msg := kafka.Message{
+				Key:     []byte(o.OrderUID),
+				Value:   payload,
+				Headers: []kafka.Header{{Key: "content-type", Value: []byte(contentType)}},
+			}
 
 			ok := false
 			for i := 0; i < *retries; i++ {
@@ -1,12 +1,17 @@
 package db
 
 import (
+	"context"
 	"embed"
+	"fmt"
+	"io/fs"
 	"os"
 
 	"github.com/RozmiDan/wb_tech_testtask/internal/config"
 	"github.com/jackc/pgx"
 	"github.com/jackc/pgx/stdlib"
+	stdlibv5 "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pressly/goose/v3"
 	"go.uber.org/zap"
 )
@@ -40,3 +45,57 @@ func SetupPostgres(cfg *config.Config, logger *zap.Logger) {
 		os.Exit(1)
 	}
 }
+
+// Up применяет встроенные миграции через уже открытый пул.
+// Используется интеграционными тестами с локальным PostgreSQL.
+func Up(pool *pgxpool.Pool) error {
+	goose.SetBaseFS(embedMigrations)
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+
+	sqlDB := stdlibv5.OpenDBFromPool(pool)
+	defer func() { _ = sqlDB.Close() }()
+
+	return goose.Up(sqlDB, "migrations")
+}
+
+const selectAppliedVersion = `
+	SELECT COALESCE(MAX(version_id), 0)
+	FROM goose_db_version
+	WHERE is_applied
+`
+
+// LatestVersion возвращает версию последней встроенной в бинарь миграции.
+func LatestVersion() (int64, error) {
+	entries, err := fs.ReadDir(embedMigrations, "migrations")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, e := range entries {
+		v, err := goose.NumericComponent(e.Name())
+		if err != nil {
+			continue
+		}
+		latest = max(latest, v)
+	}
+	return latest, nil
+}
+
+// CheckVersion сверяет применённую версию схемы с последней встроенной миграцией.
+func CheckVersion(ctx context.Context, pool *pgxpool.Pool) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return fmt.Errorf("read embedded migrations: %w", err)
+	}
+
+	var applied int64
+	if err := pool.QueryRow(ctx, selectAppliedVersion).Scan(&applied); err != nil {
+		return fmt.Errorf("read applied version: %w", err)
+	}
+	if applied < latest {
+		return fmt.Errorf("schema version %d is behind latest migration %d", applied, latest)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS failed_orders (
+  id           BIGSERIAL PRIMARY KEY,
+  order_uid    TEXT,
+  topic        TEXT NOT NULL,
+  partition    INTEGER NOT NULL,
+  kafka_offset BIGINT NOT NULL,
+  payload      BYTEA NOT NULL,
+  reason       TEXT NOT NULL,
+  error        TEXT,
+  attempts     INTEGER NOT NULL,
+  request_id   TEXT,
+  failed_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_failed_orders_order_uid
+  ON failed_orders (order_uid);
+
+-- +goose Down
+DROP TABLE IF EXISTS failed_orders;
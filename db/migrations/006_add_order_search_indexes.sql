@@ -0,0 +1,48 @@
+-- +goose Up
+-- заменяет idx_orders_created_at_desc: префикс (created_at DESC) покрывает те же запросы,
+-- а order_uid нужен курсорной пагинации
+CREATE INDEX IF NOT EXISTS idx_orders_created_at_uid
+  ON orders (created_at DESC, order_uid DESC);
+
+DROP INDEX IF EXISTS idx_orders_created_at_desc;
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id
+  ON orders (customer_id, created_at DESC, order_uid DESC);
+
+CREATE INDEX IF NOT EXISTS idx_orders_track_number
+  ON orders (track_number);
+
+CREATE INDEX IF NOT EXISTS idx_orders_delivery_service
+  ON orders (delivery_service);
+
+CREATE INDEX IF NOT EXISTS idx_orders_date_created
+  ON orders (date_created);
+
+CREATE INDEX IF NOT EXISTS idx_payments_provider_bank
+  ON payments (provider, bank);
+
+CREATE INDEX IF NOT EXISTS idx_payments_bank
+  ON payments (bank);
+
+CREATE INDEX IF NOT EXISTS idx_items_order_uid
+  ON items (order_uid);
+
+CREATE INDEX IF NOT EXISTS idx_items_nm_id
+  ON items (nm_id);
+
+CREATE INDEX IF NOT EXISTS idx_items_brand
+  ON items (brand);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_items_brand;
+DROP INDEX IF EXISTS idx_items_nm_id;
+DROP INDEX IF EXISTS idx_items_order_uid;
+DROP INDEX IF EXISTS idx_payments_bank;
+DROP INDEX IF EXISTS idx_payments_provider_bank;
+DROP INDEX IF EXISTS idx_orders_date_created;
+DROP INDEX IF EXISTS idx_orders_delivery_service;
+DROP INDEX IF EXISTS idx_orders_track_number;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+CREATE INDEX IF NOT EXISTS idx_orders_created_at_desc
+  ON orders (created_at DESC);
+DROP INDEX IF EXISTS idx_orders_created_at_uid;
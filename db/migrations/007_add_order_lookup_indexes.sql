@@ -0,0 +1,6 @@
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_payments_transaction
+  ON payments (transaction);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payments_transaction;
@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'created'
+  CHECK (status IN ('created', 'paid', 'assembled', 'shipped', 'delivered', 'cancelled', 'returned'));
+
+ALTER TABLE items
+  ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'created'
+  CHECK (state IN ('created', 'paid', 'assembled', 'shipped', 'delivered', 'cancelled', 'returned'));
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+  id          BIGSERIAL PRIMARY KEY,
+  order_uid   TEXT NOT NULL
+              REFERENCES orders(order_uid) ON DELETE CASCADE,
+  chrt_id     BIGINT,
+  from_status TEXT NOT NULL,
+  to_status   TEXT NOT NULL,
+  reason      TEXT,
+  source      TEXT NOT NULL,
+  request_id  TEXT,
+  changed_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_uid
+  ON order_status_history (order_uid, changed_at);
+
+-- заменяет idx_items_order_uid: выборки позиций заказа идут по левому префиксу
+CREATE INDEX IF NOT EXISTS idx_items_order_uid_chrt_id
+  ON items (order_uid, chrt_id);
+
+DROP INDEX IF EXISTS idx_items_order_uid;
+
+-- +goose Down
+CREATE INDEX IF NOT EXISTS idx_items_order_uid
+  ON items (order_uid);
+DROP INDEX IF EXISTS idx_items_order_uid_chrt_id;
+DROP TABLE IF EXISTS order_status_history;
+ALTER TABLE items DROP COLUMN IF EXISTS state;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS outbox (
+  id           BIGSERIAL PRIMARY KEY,
+  event_type   TEXT NOT NULL,
+  aggregate_id TEXT NOT NULL,
+  payload      JSONB NOT NULL,
+  request_id   TEXT,
+  created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  sent_at      TIMESTAMP WITH TIME ZONE,
+  attempts     INT NOT NULL DEFAULT 0,
+  last_error   TEXT
+);
+
+-- relay выбирает только неотправленные события в порядке записи
+CREATE INDEX IF NOT EXISTS idx_outbox_unsent
+  ON outbox (id) WHERE sent_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_unsent;
+DROP TABLE IF EXISTS outbox;
@@ -2,9 +2,11 @@ package app
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -13,11 +15,17 @@ import (
 	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/server"
 	"github.com/RozmiDan/wb_tech_testtask/internal/controller/kafka"
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/RozmiDan/wb_tech_testtask/internal/health"
+	prom_metrics "github.com/RozmiDan/wb_tech_testtask/internal/metrics"
+	"github.com/RozmiDan/wb_tech_testtask/internal/outbox"
 	"github.com/RozmiDan/wb_tech_testtask/internal/repo/postgre"
+	"github.com/RozmiDan/wb_tech_testtask/internal/repo/rediscache"
 	"github.com/RozmiDan/wb_tech_testtask/internal/usecase"
 	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
 	"github.com/RozmiDan/wb_tech_testtask/pkg/logger"
 	"github.com/RozmiDan/wb_tech_testtask/pkg/postgres"
+	"github.com/RozmiDan/wb_tech_testtask/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -42,33 +50,211 @@ func Run(cfg *config.Config) {
 	defer rootCancel()
 
 	// repo
-	repo := postgre.New(pg, logger)
+	repo := postgre.New(pg, logger, postgre.Outbox(cfg.OutboxEnabled))
+
+	// локальные LRU других реплик не узнают об изменениях заказа, поэтому
+	// с общим уровнем их записи обязаны устаревать сами
+	if cfg.RedisURL != "" && cfg.CacheTTL <= 0 {
+		logger.Error("CACHE_TTL must be set when REDIS_URL is configured: local caches of other replicas are never invalidated")
+		os.Exit(1)
+	}
 
 	// cache
-	cache := lru_cache.NewLruCache[string, *entity.OrderResponse](cfg.CacheCap, nil)
+	cacheOpts := []lru_cache.Option[string, *entity.OrderResponse]{
+		lru_cache.WithOnEvict(func(_ string, _ *entity.OrderResponse, reason lru_cache.EvictReason) {
+			prom_metrics.CacheRemovals.WithLabelValues("orders", reason.String()).Inc()
+		}),
+	}
+	// списки по вторичным ключам сбрасываются только локальным AddOrderInfo,
+	// заказы других реплик и консьюмеров они увидят лишь после TTL
+	if cfg.CacheRefsTTL <= 0 {
+		logger.Error("CACHE_REFS_TTL must be positive")
+		os.Exit(1)
+	}
+	refsOpts := []lru_cache.Option[string, *entity.OrderRefs]{
+		lru_cache.WithOnEvict(func(_ string, _ *entity.OrderRefs, reason lru_cache.EvictReason) {
+			prom_metrics.CacheRemovals.WithLabelValues("order_refs", reason.String()).Inc()
+		}),
+		lru_cache.WithTTL[string, *entity.OrderRefs](cfg.CacheRefsTTL),
+		lru_cache.WithCleanupInterval[string, *entity.OrderRefs](cfg.CacheCleanupInterval),
+	}
+	if cfg.CacheTTL > 0 {
+		cacheOpts = append(cacheOpts,
+			lru_cache.WithTTL[string, *entity.OrderResponse](cfg.CacheTTL),
+			lru_cache.WithCleanupInterval[string, *entity.OrderResponse](cfg.CacheCleanupInterval),
+		)
+	}
+	if cfg.CacheMaxBytes > 0 {
+		cacheOpts = append(cacheOpts, lru_cache.WithMaxCost[string](cfg.CacheMaxBytes, (*entity.OrderResponse).ApproxSize))
+	}
+	if cfg.CacheMaxBytes > 0 && cfg.CacheMaxBytes < int64(max(cfg.CacheShards, 1)) {
+		logger.Error("CACHE_MAX_BYTES must be at least CACHE_SHARDS")
+		os.Exit(1)
+	}
+	cache := lru_cache.NewShardedCache(cfg.CacheCap, cfg.CacheShards, nil, cacheOpts...)
+	defer cache.Close()
+	if cfg.CacheMaxBytes > 0 && cfg.CacheShards > 1 {
+		// лимит объёма делится между шардами: заказ больше доли шарда не кэшируется
+		logger.Info("cache max bytes split between shards", zap.Int64("max_order_bytes", cache.MaxEntryCost()))
+	}
+	refsCache := lru_cache.NewShardedCache(cfg.CacheRefsCap, cfg.CacheShards, nil, refsOpts...)
+	defer refsCache.Close()
+
+	// общий для реплик уровень кэша поверх локального LRU
+	var orderCache usecase.OrderCache = cache
+	if cfg.RedisURL != "" {
+		rds, err := redis.New(cfg.RedisURL)
+		if err != nil {
+			logger.Error("Cant configure redis, using local cache only", zap.Error(err))
+		} else {
+			defer func() { _ = rds.Close() }()
+			orderCache = rediscache.New(cache, rds.Client, logger,
+				rediscache.TTL(cfg.RedisCacheTTL),
+				rediscache.OpTimeout(cfg.RedisOpTimeout),
+				rediscache.Cooldown(cfg.RedisCooldown),
+			)
+		}
+	}
+
+	if cfg.CacheFetchTimeout <= 0 {
+		logger.Error("CACHE_FETCH_TIMEOUT must be positive")
+		os.Exit(1)
+	}
+	ucOpts := []usecase.Option{
+		usecase.WithRefsCache(refsCache),
+		usecase.WithFetchTimeout(cfg.CacheFetchTimeout),
+	}
+	if cfg.CacheNotFoundTTL > 0 {
+		notFound := lru_cache.NewShardedCache(cfg.CacheNotFoundCap, cfg.CacheShards, struct{}{},
+			lru_cache.WithTTL[string, struct{}](cfg.CacheNotFoundTTL),
+			lru_cache.WithCleanupInterval[string, struct{}](cfg.CacheNotFoundTTL),
+		)
+		defer notFound.Close()
+		ucOpts = append(ucOpts, usecase.WithNotFoundCache(notFound))
+	}
 
 	// usecase
-	uc := usecase.New(logger, repo, cache)
+	uc := usecase.New(logger, repo, orderCache, ucOpts...)
 
 	// Kafka
 	kafkaConsumer := kafka.NewConsumer(cfg, uc, logger)
 
+	// metrics
+	prometheus.MustRegister(
+		prom_metrics.NewPgxPoolCollector(pg.Pool),
+		prom_metrics.NewCacheCollector("orders", func() prom_metrics.CacheStats {
+			s := cache.Stats()
+			return prom_metrics.CacheStats{
+				Hits: s.Hits, Misses: s.Misses, Evictions: s.Evictions, Expired: s.Expired, Size: s.Size, Cost: s.Cost,
+			}
+		}),
+		prom_metrics.NewCacheCollector("order_refs", func() prom_metrics.CacheStats {
+			s := refsCache.Stats()
+			return prom_metrics.CacheStats{
+				Hits: s.Hits, Misses: s.Misses, Evictions: s.Evictions, Expired: s.Expired, Size: s.Size, Cost: s.Cost,
+			}
+		}),
+		prom_metrics.NewKafkaCollector(cfg.KafkaTopic, func() prom_metrics.KafkaStats {
+			s := kafkaConsumer.Stats()
+			return prom_metrics.KafkaStats{
+				Consumed:    s.Consumed,
+				Committed:   s.Committed,
+				Rejected:    s.Rejected,
+				Retried:     s.Retried,
+				Parked:      s.Parked,
+				Failed:      s.Failed,
+				SinkRetried: s.SinkRetried,
+				Lag:         s.Lag,
+				Offset:      s.Offset,
+				QueueLength: s.QueueLength,
+			}
+		}),
+	)
+
+	// outbox relay
+	relayDone := make(chan struct{})
+	var outboxPub *outbox.KafkaPublisher
+	if cfg.OutboxEnabled {
+		outboxPub = outbox.NewKafkaPublisher(outbox.NewKafkaWriter(cfg.KafkaBrokers, cfg.OutboxTopic))
+		relay := outbox.New(repo, outboxPub, logger,
+			outbox.BatchSize(cfg.OutboxBatchSize),
+			outbox.Interval(cfg.OutboxPollInterval),
+			outbox.MaxBackoff(cfg.OutboxMaxBackoff),
+			outbox.Retention(cfg.OutboxRetention),
+		)
+		go func() {
+			defer close(relayDone)
+			relay.Run(rootCtx)
+		}()
+	} else {
+		close(relayDone)
+	}
+
+	// health
+	var cacheWarmed atomic.Bool
+	checker := health.New(cfg.HealthTimeout)
+	checker.Register("postgres", pg.Pool.Ping)
+	checker.Register("kafka", kafkaConsumer.Ping)
+	checker.Register("migrations", func(ctx context.Context) error {
+		return db.CheckVersion(ctx, pg.Pool)
+	})
+	checker.Register("cache_warmup", func(context.Context) error {
+		if !cacheWarmed.Load() {
+			return errors.New("cache warm-up in progress")
+		}
+		return nil
+	})
+
+	// снимок кэша содержит персональные данные и без ключа шифрования не пишется
+	var sealer *snapshotSealer
+	if cfg.CacheSnapshotPath != "" {
+		if sealer, err = newSnapshotSealer(cfg.CacheSnapshotKey); err != nil {
+			logger.Warn("cache snapshot disabled", zap.Error(err))
+		}
+	}
+
+	// снимок горячих заказов читается с диска быстро и до старта консьюмера,
+	// чтобы не перезаписать более свежие заказы и статусы, пришедшие из Kafka
+	restored := sealer != nil && restoreCacheSnapshot(cache, sealer, cfg.CacheSnapshotPath, cfg.CacheSnapshotMaxAge, logger)
+	cacheWarmed.Store(restored)
+
+	consumerDone := make(chan struct{})
 	go func() {
+		defer close(consumerDone)
 		kafkaConsumer.Start(rootCtx, cfg)
 		logger.Info("kafka consumer stopped")
 	}()
 
-	// прогрев кэша
-	warmCtx, cancel := context.WithTimeout(rootCtx, 2*time.Second)
-	if err := uc.WarmCacheLatest(warmCtx, cfg.CacheCap); err != nil {
-		logger.Warn("cache warm failed", zap.Error(err))
-	} else {
-		logger.Warn("cache warm success")
+	// server
+	server, err := server.InitServer(cfg, logger, uc, checker)
+	if err != nil {
+		logger.Error("Cant configure http server", zap.Error(err))
+		os.Exit(1)
 	}
-	cancel()
 
-	// server
-	server := server.InitServer(cfg, logger, uc)
+	go func() {
+		logger.Info("starting server", zap.String("port", cfg.HTTPPort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Server error", zap.Error(err))
+			os.Exit(1)
+		}
+	}()
+
+	// без снимка кэш прогревается последними заказами из БД в фоне: сервер уже
+	// отвечает на /healthz, а /readyz отдаёт 503, пока прогрев не закончится
+	if !restored {
+		go func() {
+			warmCtx, cancel := context.WithTimeout(rootCtx, cfg.CacheWarmupTimeout)
+			defer cancel()
+			if err := uc.WarmCacheLatest(warmCtx, cfg.CacheCap); err != nil {
+				logger.Warn("cache warm failed", zap.Error(err))
+			} else {
+				logger.Info("cache warm success")
+			}
+			// неудачный прогрев не блокирует трафик: кэш заполнится по мере запросов
+			cacheWarmed.Store(true)
+		}()
+	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -84,8 +270,19 @@ func Run(cfg *config.Config) {
 	<-stop
 	logger.Info("Shutting down server...")
 
+	// сначала readiness начинает отдавать 503, чтобы балансировщик снял с нас трафик
+	checker.SetShuttingDown()
+	time.Sleep(cfg.HTTPShutdownDrain)
+
 	rootCancel()
+	// ждём, пока воркеры консьюмера допишут кэш, и только потом закрываем reader
+	<-consumerDone
 	_ = kafkaConsumer.Close()
+	// неотправленные события останутся в outbox и уйдут после рестарта
+	<-relayDone
+	if outboxPub != nil {
+		_ = outboxPub.Close()
+	}
 
 	ctx, cancel2 := context.WithTimeout(context.Background(), cfg.HTTPTimeout*time.Second)
 	defer cancel2()
@@ -96,5 +293,10 @@ func Run(cfg *config.Config) {
 		logger.Info("Server gracefully stopped")
 	}
 
+	// HTTP и Kafka остановлены — содержимое кэша больше не меняется
+	if sealer != nil {
+		saveCacheSnapshot(cache, sealer, cfg.CacheSnapshotPath, logger)
+	}
+
 	logger.Info("Finishing programm")
 }
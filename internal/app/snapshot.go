@@ -0,0 +1,161 @@
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"go.uber.org/zap"
+)
+
+// minSnapshotKeyLen — минимальная длина CACHE_SNAPSHOT_KEY.
+const minSnapshotKeyLen = 32
+
+// snapshotSealer шифрует заказы снимка AES-256-GCM: в них персональные данные
+// получателя, а каталог снимка смонтирован на хост. В открытом виде в файле
+// остаются только order_uid и время создания снимка.
+type snapshotSealer struct {
+	aead cipher.AEAD
+}
+
+// newSnapshotSealer выводит ключ AES-256 из CACHE_SNAPSHOT_KEY.
+func newSnapshotSealer(key string) (*snapshotSealer, error) {
+	if len(key) < minSnapshotKeyLen {
+		return nil, fmt.Errorf("snapshot key must be at least %d bytes", minSnapshotKeyLen)
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotSealer{aead: aead}, nil
+}
+
+// seal шифрует заказ; order_uid входит в подпись, чтобы запись нельзя было
+// переложить под чужой ключ.
+func (s *snapshotSealer) seal(order *entity.OrderResponse) ([]byte, error) {
+	plain, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plain, []byte(order.OrderUID)), nil
+}
+
+func (s *snapshotSealer) open(orderUID string, sealed []byte) (*entity.OrderResponse, error) {
+	n := s.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("sealed entry is too short")
+	}
+	plain, err := s.aead.Open(nil, sealed[:n], sealed[n:], []byte(orderUID))
+	if err != nil {
+		return nil, err
+	}
+	order := &entity.OrderResponse{}
+	if err := json.Unmarshal(plain, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// restoreCacheSnapshot загружает снимок горячих заказов в локальный кэш.
+// Возвращает false, если снимка нет, он устарел, повреждён или зашифрован
+// другим ключом — тогда вызывающий прогревает кэш последними заказами из БД.
+func restoreCacheSnapshot(cache *lru_cache.ShardedCache[string, *entity.OrderResponse],
+	sealer *snapshotSealer, path string, maxAge time.Duration, logger *zap.Logger) bool {
+	logger = logger.With(zap.String("func", "restoreCacheSnapshot"), zap.String("path", path))
+
+	entries, err := lru_cache.ReadSnapshot[string, []byte](path, maxAge)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		logger.Info("cache snapshot not found")
+		return false
+	case err != nil:
+		logger.Warn("cache snapshot skipped", zap.Error(err))
+		return false
+	case len(entries) == 0:
+		logger.Info("cache snapshot is empty")
+		return false
+	}
+
+	// снимок идёт от недавно использованных к давним (при CACHE_SHARDS>1 шарды
+	// чередуются по рангу, см. ShardedCache.All): кладём с конца, чтобы самые
+	// горячие заказы оказались в начале LRU и пережили вытеснение, даже если
+	// кэш меньше снимка или ключи легли в шарды иначе, чем до рестарта
+	restored := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		// снимок может быть старше записи, уже попавшей в кэш
+		if cache.Contains(e.Key) {
+			continue
+		}
+		order, err := sealer.open(e.Key, e.Value)
+		if err != nil {
+			// ключ сменили или файл подменён — не доверяем снимку целиком
+			logger.Warn("cache snapshot cant be decrypted, skipped", zap.Error(err))
+			return restored > 0
+		}
+		if order.OrderUID != e.Key {
+			continue
+		}
+		cache.Put(e.Key, order)
+		restored++
+	}
+	logger.Info("cache restored from snapshot", zap.Int("count", restored))
+
+	return restored > 0
+}
+
+// saveCacheSnapshot сохраняет содержимое локального кэша в порядке LRU;
+// шарды чередуются, так что порядок близок к глобальному LRU.
+func saveCacheSnapshot(cache *lru_cache.ShardedCache[string, *entity.OrderResponse],
+	sealer *snapshotSealer, path string, logger *zap.Logger) {
+	logger = logger.With(zap.String("func", "saveCacheSnapshot"), zap.String("path", path))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		logger.Error("cant create snapshot dir", zap.Error(err))
+		return
+	}
+	n, err := lru_cache.WriteSnapshot(path, sealAll(cache.All(), sealer, logger))
+	if err != nil {
+		logger.Error("cache snapshot failed", zap.Error(err))
+		return
+	}
+	logger.Info("cache snapshot saved", zap.Int("count", n))
+}
+
+// sealAll шифрует записи кэша по мере обхода; незашифрованные пропускаются.
+func sealAll(all iter.Seq2[string, *entity.OrderResponse], sealer *snapshotSealer, logger *zap.Logger) iter.Seq2[string, []byte] {
+	return func(yield func(string, []byte) bool) {
+		for k, v := range all {
+			if v == nil || k != v.OrderUID {
+				continue
+			}
+			sealed, err := sealer.seal(v)
+			if err != nil {
+				logger.Warn("cant seal cached order, skipped", zap.String("order_uid", k), zap.Error(err))
+				continue
+			}
+			if !yield(k, sealed) {
+				return
+			}
+		}
+	}
+}
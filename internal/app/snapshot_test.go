@@ -0,0 +1,78 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCacheSnapshotIsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	sealer, err := newSnapshotSealer("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+
+	src := lru_cache.NewShardedCache[string, *entity.OrderResponse](10, 1, nil)
+	defer src.Close()
+	src.Put("a", &entity.OrderResponse{OrderUID: "a", Delivery: entity.DeliveryPublic{Name: "Test Testov", Phone: "+9720000000"}})
+	saveCacheSnapshot(src, sealer, path, zap.NewNop())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "Testov")
+	require.NotContains(t, string(raw), "+9720000000")
+
+	dst := lru_cache.NewShardedCache[string, *entity.OrderResponse](10, 1, nil)
+	defer dst.Close()
+	require.True(t, restoreCacheSnapshot(dst, sealer, path, time.Hour, zap.NewNop()))
+	require.Equal(t, "Test Testov", dst.Get("a").Delivery.Name)
+
+	// запись, уже обновлённая после старта, не перезаписывается снимком
+	fresh := lru_cache.NewShardedCache[string, *entity.OrderResponse](10, 1, nil)
+	defer fresh.Close()
+	fresh.Put("a", &entity.OrderResponse{OrderUID: "a", Status: entity.StatusPaid})
+	require.False(t, restoreCacheSnapshot(fresh, sealer, path, time.Hour, zap.NewNop()))
+	require.Equal(t, entity.StatusPaid, fresh.Get("a").Status)
+
+	other, err := newSnapshotSealer("another-key-another-key-another-key")
+	require.NoError(t, err)
+	empty := lru_cache.NewShardedCache[string, *entity.OrderResponse](10, 1, nil)
+	defer empty.Close()
+	require.False(t, restoreCacheSnapshot(empty, other, path, time.Hour, zap.NewNop()), "wrong key")
+	require.Equal(t, 0, empty.Size())
+
+	_, err = newSnapshotSealer("short")
+	require.Error(t, err)
+}
+
+func TestCacheSnapshotKeepsHottestAcrossShards(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	sealer, err := newSnapshotSealer("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+
+	const total = 256
+	src := lru_cache.NewShardedCache[string, *entity.OrderResponse](total, 8, nil)
+	defer src.Close()
+	for i := range total {
+		uid := strconv.Itoa(i)
+		src.Put(uid, &entity.OrderResponse{OrderUID: uid})
+	}
+	saveCacheSnapshot(src, sealer, path, zap.NewNop())
+
+	// кэш после рестарта меньше снимка, и ключи ложатся в шарды по другому seed
+	dst := lru_cache.NewShardedCache[string, *entity.OrderResponse](32, 8, nil)
+	defer dst.Close()
+	require.True(t, restoreCacheSnapshot(dst, sealer, path, time.Hour, zap.NewNop()))
+
+	for uid := range dst.All() {
+		i, err := strconv.Atoi(uid)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, i, total/2, "only recently used orders survive the restore")
+	}
+}
@@ -0,0 +1,70 @@
+package codec
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+)
+
+//go:embed schema/order.avsc
+var orderAvroSchema string
+
+var orderSchema = avro.MustParse(orderAvroSchema)
+
+// поля записи Avro совпадают с JSON-именами полей entity, поэтому
+// структуры заказа разбираются напрямую по тегам json
+var avroAPI = avro.Config{TagKey: "json"}.Freeze()
+
+// DecodeAvro разбирает Avro Object Container с одним заказом. Схема писателя
+// берётся из заголовка контейнера: поля, которых нет в entity.OrderInfo,
+// пропускаются, поэтому продюсер может добавлять новые поля без координации.
+func DecodeAvro(value []byte) (*entity.OrderInfo, error) {
+	dec, err := ocf.NewDecoder(bytes.NewReader(value), ocf.WithDecoderConfig(avroAPI))
+	if err != nil {
+		return nil, err
+	}
+	if !dec.HasNext() {
+		if err := dec.Error(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("avro container has no records")
+	}
+
+	order := &entity.OrderInfo{}
+	if err := dec.Decode(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// EncodeAvro сериализует заказ в Avro Object Container со схемой schema/order.avsc.
+func EncodeAvro(order *entity.OrderInfo) ([]byte, error) {
+	// ocf пишет заголовок контейнера тем же конфигом, что и записи, а заголовку
+	// нужны теги avro: поэтому запись сначала кодируется по тегам json
+	// и пересобирается в map, которую контейнер пишет конфигом по умолчанию
+	raw, err := avroAPI.Marshal(orderSchema, order)
+	if err != nil {
+		return nil, err
+	}
+	var record map[string]any
+	if err := avro.Unmarshal(orderSchema, raw, &record); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc, err := ocf.NewEncoderWithSchema(orderSchema, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(record); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
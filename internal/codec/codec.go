@@ -0,0 +1,54 @@
+// Package codec кодирует и декодирует заказы во всех форматах, которые принимает
+// консьюмер: JSON, Protobuf (schema/order.proto) и Avro Object Container
+// со встроенной схемой (schema/order.avsc).
+package codec
+
+//go:generate protoc --proto_path=schema --go_out=orderpb --go_opt=paths=source_relative order.proto
+
+import (
+	"encoding/json"
+	"mime"
+	"strings"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+)
+
+// значения заголовка content-type
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeAvro     = "application/avro"
+)
+
+// NormalizeContentType приводит значение заголовка к каноничному виду:
+// отбрасывает параметры (charset и т.п.), приводит к нижнему регистру и
+// сворачивает распространённые синонимы. Пустое значение означает JSON.
+func NormalizeContentType(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ContentTypeJSON
+	}
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		mediaType = strings.ToLower(value)
+	}
+	switch mediaType {
+	case "application/protobuf", "application/vnd.google.protobuf":
+		return ContentTypeProtobuf
+	case "avro/binary", "application/vnd.apache.avro+binary":
+		return ContentTypeAvro
+	}
+	return mediaType
+}
+
+// Encode кодирует заказ в формат contentType; используется producer'ом для тестов.
+func Encode(contentType string, order *entity.OrderInfo) ([]byte, error) {
+	switch NormalizeContentType(contentType) {
+	case ContentTypeProtobuf:
+		return EncodeProtobuf(order)
+	case ContentTypeAvro:
+		return EncodeAvro(order)
+	default:
+		return json.Marshal(order)
+	}
+}
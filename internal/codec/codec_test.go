@@ -0,0 +1,124 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrder() *entity.OrderInfo {
+	return &entity.OrderInfo{
+		OrderUID:          "b563feb7b2b84b6test",
+		TrackNumber:       "WBILMTESTTRACK",
+		Entry:             "WBIL",
+		Locale:            "en",
+		InternalSignature: "",
+		CustomerID:        "test",
+		DeliveryService:   "meest",
+		ShardKey:          "9",
+		SmID:              99,
+		DateCreated:       time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:          "1",
+		Delivery: entity.DeliveryInfo{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: entity.PaymentInfo{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []entity.ItemInfo{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			Rid:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	decoders := map[string]func([]byte) (*entity.OrderInfo, error){
+		ContentTypeProtobuf: DecodeProtobuf,
+		ContentTypeAvro:     DecodeAvro,
+	}
+	for contentType, decode := range decoders {
+		value, err := Encode(contentType, testOrder())
+		require.NoError(t, err, contentType)
+
+		got, err := decode(value)
+		require.NoError(t, err, contentType)
+		require.Equal(t, testOrder(), got, contentType)
+	}
+}
+
+func TestDecodeAvroSkipsUnknownWriterFields(t *testing.T) {
+	t.Parallel()
+
+	// схема писателя с новым полем, о котором консьюмер ещё не знает
+	schema := `{"type": "record", "name": "Order", "fields": [
+		{"name": "order_uid", "type": "string"},
+		{"name": "sales_channel", "type": "string"},
+		{"name": "sm_id", "type": "long"}
+	]}`
+	var buf bytes.Buffer
+	enc, err := ocf.NewEncoder(schema, &buf)
+	require.NoError(t, err)
+	require.NoError(t, enc.Encode(map[string]any{"order_uid": "uid-1", "sales_channel": "app", "sm_id": int64(7)}))
+	require.NoError(t, enc.Close())
+
+	order, err := DecodeAvro(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, "uid-1", order.OrderUID)
+	require.Equal(t, 7, order.SmID)
+}
+
+func TestDecodeGarbage(t *testing.T) {
+	t.Parallel()
+
+	_, err := DecodeAvro([]byte(`{"order_uid": "json"}`))
+	require.Error(t, err)
+
+	_, err = DecodeProtobuf([]byte{0xff, 0xff, 0xff})
+	require.Error(t, err)
+}
+
+func TestNormalizeContentType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"":                                ContentTypeJSON,
+		"application/json; charset=utf-8": ContentTypeJSON,
+		"Application/X-Protobuf":          ContentTypeProtobuf,
+		"application/protobuf":            ContentTypeProtobuf,
+		"avro/binary":                     ContentTypeAvro,
+		"application/avro":                ContentTypeAvro,
+		"text/csv":                        "text/csv",
+	}
+	for in, want := range cases {
+		require.Equal(t, want, NormalizeContentType(in), in)
+	}
+}
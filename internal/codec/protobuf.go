@@ -0,0 +1,138 @@
+package codec
+
+import (
+	"github.com/RozmiDan/wb_tech_testtask/internal/codec/orderpb"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DecodeProtobuf разбирает сообщение orderpb.Order.
+func DecodeProtobuf(value []byte) (*entity.OrderInfo, error) {
+	var pb orderpb.Order
+	if err := proto.Unmarshal(value, &pb); err != nil {
+		return nil, err
+	}
+	return orderFromProto(&pb), nil
+}
+
+// EncodeProtobuf сериализует заказ в orderpb.Order.
+func EncodeProtobuf(order *entity.OrderInfo) ([]byte, error) {
+	return proto.Marshal(orderToProto(order))
+}
+
+func orderFromProto(pb *orderpb.Order) *entity.OrderInfo {
+	order := &entity.OrderInfo{
+		OrderUID:          pb.GetOrderUid(),
+		TrackNumber:       pb.GetTrackNumber(),
+		Entry:             pb.GetEntry(),
+		Locale:            pb.GetLocale(),
+		InternalSignature: pb.GetInternalSignature(),
+		CustomerID:        pb.GetCustomerId(),
+		DeliveryService:   pb.GetDeliveryService(),
+		ShardKey:          pb.GetShardkey(),
+		SmID:              int(pb.GetSmId()),
+		OofShard:          pb.GetOofShard(),
+	}
+	if pb.GetDateCreated() != nil {
+		order.DateCreated = pb.GetDateCreated().AsTime()
+	}
+	if d := pb.GetDelivery(); d != nil {
+		order.Delivery = entity.DeliveryInfo{
+			Name:    d.GetName(),
+			Phone:   d.GetPhone(),
+			Zip:     d.GetZip(),
+			City:    d.GetCity(),
+			Address: d.GetAddress(),
+			Region:  d.GetRegion(),
+			Email:   d.GetEmail(),
+		}
+	}
+	if p := pb.GetPayment(); p != nil {
+		order.Payment = entity.PaymentInfo{
+			Transaction:  p.GetTransaction(),
+			RequestID:    p.GetRequestId(),
+			Currency:     p.GetCurrency(),
+			Provider:     p.GetProvider(),
+			Amount:       p.GetAmount(),
+			PaymentDT:    p.GetPaymentDt(),
+			Bank:         p.GetBank(),
+			DeliveryCost: p.GetDeliveryCost(),
+			GoodsTotal:   p.GetGoodsTotal(),
+			CustomFee:    p.GetCustomFee(),
+		}
+	}
+	order.Items = make([]entity.ItemInfo, 0, len(pb.GetItems()))
+	for _, it := range pb.GetItems() {
+		order.Items = append(order.Items, entity.ItemInfo{
+			ChrtID:      it.GetChrtId(),
+			TrackNumber: it.GetTrackNumber(),
+			Price:       it.GetPrice(),
+			Rid:         it.GetRid(),
+			Name:        it.GetName(),
+			Sale:        it.GetSale(),
+			Size:        it.GetSize(),
+			TotalPrice:  it.GetTotalPrice(),
+			NmID:        it.GetNmId(),
+			Brand:       it.GetBrand(),
+			Status:      it.GetStatus(),
+		})
+	}
+	return order
+}
+
+func orderToProto(order *entity.OrderInfo) *orderpb.Order {
+	pb := &orderpb.Order{
+		OrderUid:          order.OrderUID,
+		TrackNumber:       order.TrackNumber,
+		Entry:             order.Entry,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerId:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.ShardKey,
+		SmId:              int64(order.SmID),
+		OofShard:          order.OofShard,
+		Delivery: &orderpb.Delivery{
+			Name:    order.Delivery.Name,
+			Phone:   order.Delivery.Phone,
+			Zip:     order.Delivery.Zip,
+			City:    order.Delivery.City,
+			Address: order.Delivery.Address,
+			Region:  order.Delivery.Region,
+			Email:   order.Delivery.Email,
+		},
+		Payment: &orderpb.Payment{
+			Transaction:  order.Payment.Transaction,
+			RequestId:    order.Payment.RequestID,
+			Currency:     order.Payment.Currency,
+			Provider:     order.Payment.Provider,
+			Amount:       order.Payment.Amount,
+			PaymentDt:    order.Payment.PaymentDT,
+			Bank:         order.Payment.Bank,
+			DeliveryCost: order.Payment.DeliveryCost,
+			GoodsTotal:   order.Payment.GoodsTotal,
+			CustomFee:    order.Payment.CustomFee,
+		},
+		Items: make([]*orderpb.Item, 0, len(order.Items)),
+	}
+	if !order.DateCreated.IsZero() {
+		pb.DateCreated = timestamppb.New(order.DateCreated)
+	}
+	for _, it := range order.Items {
+		pb.Items = append(pb.Items, &orderpb.Item{
+			ChrtId:      it.ChrtID,
+			TrackNumber: it.TrackNumber,
+			Price:       it.Price,
+			Rid:         it.Rid,
+			Name:        it.Name,
+			Sale:        it.Sale,
+			Size:        it.Size,
+			TotalPrice:  it.TotalPrice,
+			NmId:        it.NmID,
+			Brand:       it.Brand,
+			Status:      it.Status,
+		})
+	}
+	return pb
+}
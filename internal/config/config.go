@@ -16,6 +16,30 @@ type Config struct {
 	HTTPPort        string        `env:"HTTP_PORT" envDefault:":8080"`
 	HTTPTimeout     time.Duration `env:"HTTP_TIMEOUT" envDefault:"4s"`
 	HTTPIdleTimeout time.Duration `env:"HTTP_IDLE_TIMEOUT" envDefault:"60s"`
+	// общий лимит потоковой загрузки POST /orders:bulk; HTTP_TIMEOUT действует на каждую строку
+	HTTPBulkTimeout time.Duration `env:"HTTP_BULK_TIMEOUT" envDefault:"10m"`
+	// общий лимит выгрузки GET /orders/export; HTTP_TIMEOUT действует на каждую порцию
+	HTTPExportTimeout time.Duration `env:"HTTP_EXPORT_TIMEOUT" envDefault:"30m"`
+	// сколько ждать после перевода readiness в failing, прежде чем гасить сервер
+	HTTPShutdownDrain time.Duration `env:"HTTP_SHUTDOWN_DRAIN" envDefault:"5s"`
+	HealthTimeout     time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"1s"`
+
+	// доступ к API: роли reader < writer < admin
+	AuthEnabled bool `env:"AUTH_ENABLED" envDefault:"true"`
+	// ключ:роль через запятую, ключ передаётся в заголовке X-API-Key
+	AuthAPIKeys     map[string]string `env:"AUTH_API_KEYS" envKeyValSeparator:":"`
+	AuthJWTSecret   string            `env:"AUTH_JWT_SECRET"`
+	AuthJWTIssuer   string            `env:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience string            `env:"AUTH_JWT_AUDIENCE"`
+	// роль запросов без учётных данных; пустая — такие запросы получают 401
+	AuthAnonymousRole string `env:"AUTH_ANONYMOUS_ROLE"`
+
+	// маскирование персональных данных получателя в ответах, выгрузках и UI
+	PIIMaskingEnabled bool `env:"PII_MASKING_ENABLED" envDefault:"true"`
+	// роль, начиная с которой данные отдаются без маскирования
+	PIIUnmaskedRole string `env:"PII_UNMASKED_ROLE" envDefault:"admin"`
+	// поле:режим через запятую (none|partial|full), переопределяет политику по умолчанию
+	PIIMaskPolicy map[string]string `env:"PII_MASK_POLICY" envKeyValSeparator:":"`
 
 	PostgresURL     string `env:"POSTGRES_URL"`
 	PostgresHost    string `env:"POSTGRES_HOST"`
@@ -25,7 +49,29 @@ type Config struct {
 	PostgresDB      string `env:"POSTGRES_DB"`
 	PostgresPoolMax int    `env:"POSTGRES_POOL_MAX" envDefault:"5"`
 
-	CacheCap int `env:"CACHE_CAPACITY" envDefault:"10"`
+	CacheCap             int           `env:"CACHE_CAPACITY" envDefault:"10"`
+	CacheRefsCap         int           `env:"CACHE_REFS_CAPACITY" envDefault:"100"`
+	CacheRefsTTL         time.Duration `env:"CACHE_REFS_TTL" envDefault:"30s"`
+	CacheShards          int           `env:"CACHE_SHARDS" envDefault:"1"`
+	CacheNotFoundTTL     time.Duration `env:"CACHE_NOT_FOUND_TTL" envDefault:"2s"`
+	CacheNotFoundCap     int           `env:"CACHE_NOT_FOUND_CAPACITY" envDefault:"1000"`
+	CacheFetchTimeout    time.Duration `env:"CACHE_FETCH_TIMEOUT" envDefault:"5s"`
+	CacheTTL             time.Duration `env:"CACHE_TTL" envDefault:"0s"`
+	CacheCleanupInterval time.Duration `env:"CACHE_CLEANUP_INTERVAL" envDefault:"1m"`
+	CacheMaxBytes        int64         `env:"CACHE_MAX_BYTES" envDefault:"0"`
+	// пустой CACHE_SNAPSHOT_PATH — снимок кэша не сохраняется
+	CacheSnapshotPath   string        `env:"CACHE_SNAPSHOT_PATH"`
+	CacheSnapshotMaxAge time.Duration `env:"CACHE_SNAPSHOT_MAX_AGE" envDefault:"1h"`
+	// ключ шифрования снимка (не короче 32 байт); без него снимок выключен
+	CacheSnapshotKey string `env:"CACHE_SNAPSHOT_KEY"`
+	// сколько ждать прогрева кэша последними заказами из БД
+	CacheWarmupTimeout time.Duration `env:"CACHE_WARMUP_TIMEOUT" envDefault:"2s"`
+
+	// пустой REDIS_URL — общий уровень кэша выключен
+	RedisURL       string        `env:"REDIS_URL"`
+	RedisCacheTTL  time.Duration `env:"REDIS_CACHE_TTL" envDefault:"10m"`
+	RedisOpTimeout time.Duration `env:"REDIS_OP_TIMEOUT" envDefault:"100ms"`
+	RedisCooldown  time.Duration `env:"REDIS_COOLDOWN" envDefault:"5s"`
 
 	KafkaBrokers     []string      `env:"KAFKA_BROKERS" envSeparator:","`
 	KafkaTopic       string        `env:"KAFKA_TOPIC" envDefault:"orders"`
@@ -36,6 +82,26 @@ type Config struct {
 	KafkaReadTimeout time.Duration `env:"KAFKA_READ_TIMEOUT" envDefault:"5s"`
 	KafkaDialTimeout time.Duration `env:"KAFKA_DIAL_TIMEOUT" envDefault:"5s"`
 	KafkaMsgTimeout  time.Duration `env:"KAFKA_MSG_TIMEOUT" envDefault:"3s"`
+
+	KafkaWorkers     int    `env:"KAFKA_WORKERS" envDefault:"1"`
+	KafkaWorkerQueue int    `env:"KAFKA_WORKER_QUEUE" envDefault:"64"`
+	KafkaDispatchBy  string `env:"KAFKA_DISPATCH_BY" envDefault:"partition"`
+
+	KafkaDLQEnabled bool   `env:"KAFKA_DLQ_ENABLED" envDefault:"false"`
+	KafkaDLQTopic   string `env:"KAFKA_DLQ_TOPIC" envDefault:"orders.dlq"`
+
+	KafkaRetryMaxAttempts    int           `env:"KAFKA_RETRY_MAX_ATTEMPTS" envDefault:"5"`
+	KafkaRetryInitialBackoff time.Duration `env:"KAFKA_RETRY_INITIAL_BACKOFF" envDefault:"200ms"`
+	KafkaRetryMaxBackoff     time.Duration `env:"KAFKA_RETRY_MAX_BACKOFF" envDefault:"5s"`
+	KafkaRetryMaxElapsed     time.Duration `env:"KAFKA_RETRY_MAX_ELAPSED" envDefault:"30s"`
+
+	// события order.created, записанные в outbox вместе с заказом
+	OutboxEnabled      bool          `env:"OUTBOX_ENABLED" envDefault:"true"`
+	OutboxTopic        string        `env:"OUTBOX_TOPIC" envDefault:"orders.events"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	OutboxMaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" envDefault:"30s"`
+	OutboxRetention    time.Duration `env:"OUTBOX_RETENTION" envDefault:"24h"`
 }
 
 // MustLoad парсит переменные окружения и возвращает конфигурацию или завершает выполнение при ошибке.
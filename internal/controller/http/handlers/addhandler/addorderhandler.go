@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/problem"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -17,6 +19,33 @@ type OrderInfoPoster interface {
 	AddOrderInfo(ctx context.Context, order *entity.OrderInfo) error
 }
 
+// Created — тело ответа 201; сам заказ доступен по заголовку Location.
+type Created struct {
+	OrderUID string `json:"order_uid" example:"b563feb7b2b84b6test"`
+}
+
+// Add order
+// @Summary      Add order
+// @Description  Сохраняет заказ. Заказ проверяется правилами бизнес-валидации; все нарушения
+// @Description  возвращаются разом в формате RFC 7807 (application/problem+json) со статусом 422.
+// @Description  Повторная отправка уже сохранённого заказа возвращает 409.
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        order_uid  path  string            true  "Order UID"
+// @Param        order      body  entity.OrderInfo  true  "Order"
+// @Success      201  {object}  Created             "order created, Location points to GET /order/{order_uid}"
+// @Header       201  {string}  Location            "/order/{order_uid}"
+// @Failure      400  {object}  response.ErrorBody  "invalid order_uid or malformed JSON"
+// @Failure      409  {object}  response.ErrorBody  "order already exists"
+// @Failure      422  {object}  problem.Details     "validation failed"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{order_uid} [post]
 func New(log *zap.Logger, uc OrderInfoPoster) http.HandlerFunc {
 	baselog := log.With(zap.String("handler", "PingHandler"))
 
@@ -34,7 +63,7 @@ func New(log *zap.Logger, uc OrderInfoPoster) http.HandlerFunc {
 		orderUID := chi.URLParam(r, "order_uid")
 		if err := uidParser(orderUID); err != nil {
 			logger.Warn("invalid order_uid", zap.String("order_uid", orderUID))
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, err.Error())
 
 			return
 		}
@@ -52,26 +81,31 @@ func New(log *zap.Logger, uc OrderInfoPoster) http.HandlerFunc {
 		dec.DisallowUnknownFields()
 
 		if err := dec.Decode(&order); err != nil {
+			if verr := problem.DecodeViolation(err); verr != nil {
+				writeProblem(w, problem.Validation(r, verr), logger)
+
+				return
+			}
 			if errors.Is(err, io.EOF) {
-				http.Error(w, "empty body", http.StatusBadRequest)
+				response.Error(w, r, logger, http.StatusBadRequest, response.CodeMalformedBody, "empty body")
 
 				return
 			}
-			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeMalformedBody, "invalid JSON: "+err.Error())
 
 			return
 		}
 
 		// запретим «лишние» данные после валидного JSON
 		if dec.More() {
-			http.Error(w, "unexpected data after JSON object", http.StatusBadRequest)
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeMalformedBody, "unexpected data after JSON object")
 
 			return
 		}
 
 		// 5) order_uid в body должен совпасть с path
 		if order.OrderUID != orderUID {
-			http.Error(w, "order_uid in path and body must match", http.StatusBadRequest)
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "order_uid in path and body must match")
 
 			return
 		}
@@ -81,23 +115,38 @@ func New(log *zap.Logger, uc OrderInfoPoster) http.HandlerFunc {
 		if err != nil {
 			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 				logger.Error("timeout exceeded", zap.Error(err))
-				http.Error(w, "request took longer than the timelimit", http.StatusGatewayTimeout)
+				response.Error(w, r, logger, http.StatusGatewayTimeout, response.CodeTimeout, "request took longer than the timelimit")
+				return
+			}
+
+			if errors.Is(err, entity.ErrAlreadyExists) {
+				response.Error(w, r, logger, http.StatusConflict, response.CodeAlreadyExists, "order already exists")
+
+				return
+			}
+
+			var verr *entity.ValidationError
+			if errors.As(err, &verr) {
+				logger.Info("order rejected by validation", zap.Int("violations", len(verr.Violations)))
+				writeProblem(w, problem.Validation(r, verr), logger)
+
 				return
 			}
 
 			logger.Error("failed to add order", zap.Error(err))
-			http.Error(w, "unexpected internal error", http.StatusInternalServerError)
+			response.Error(w, r, logger, http.StatusInternalServerError, response.CodeInternal, "unexpected internal error")
 
 			return
 		}
 
 		// 5) формируем успешный ответ
-		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write(nil); err != nil {
-			logger.Error("error sending the response")
+		response.Created(w, r, logger, "/order/"+orderUID, Created{OrderUID: orderUID})
+	}
+}
 
-			return
-		}
+func writeProblem(w http.ResponseWriter, p problem.Details, logger *zap.Logger) {
+	if err := problem.Write(w, p); err != nil {
+		logger.Error("error sending the response", zap.Error(err))
 	}
 }
 
@@ -0,0 +1,313 @@
+package bulkhandler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/problem"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// POST /orders:bulk
+
+const (
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// maxLineSize — предел одной строки, как у POST /order/{order_uid}
+	maxLineSize = 1 << 20
+	// maxBatchSize — сколько уже прочитанных строк сохраняется одной пачкой
+	maxBatchSize = 100
+)
+
+// Итог обработки строки.
+const (
+	ResultInserted  = "inserted"
+	ResultDuplicate = "duplicate"
+	ResultInvalid   = "invalid"
+	ResultFailed    = "failed"
+)
+
+type OrdersPoster interface {
+	AddOrders(ctx context.Context, orders []*entity.OrderInfo) []entity.OrderResult
+}
+
+// LineResult — строка ответа: итог обработки одной строки запроса.
+type LineResult struct {
+	Line     int                `json:"line" example:"1"`
+	OrderUID string             `json:"order_uid,omitempty" example:"b563feb7b2b84b6test"`
+	Result   string             `json:"result" example:"invalid" enums:"inserted,duplicate,invalid,failed"`
+	Reason   string             `json:"reason,omitempty" example:"order has 1 violation"`
+	Errors   []entity.Violation `json:"errors,omitempty"`
+}
+
+// bodyLine — непустая строка запроса.
+type bodyLine struct {
+	num int
+	raw []byte
+}
+
+// Bulk add orders
+// @Summary      Bulk add orders
+// @Description  Принимает поток NDJSON — по заказу на строку, без ограничения на размер тела — и
+// @Description  сохраняет заказы с той же валидацией, что и POST /order/{order_uid}. Строки, уже
+// @Description  пришедшие от клиента, сохраняются пачками до 100 заказов; ошибка одного заказа
+// @Description  не влияет на остальные. Результат каждой непустой строки отправляется клиенту
+// @Description  строкой NDJSON, как только сохранена её пачка: inserted, duplicate, invalid
+// @Description  (с причиной и нарушениями) или failed.
+// @Description  HTTP_TIMEOUT действует на каждую пачку, HTTP_BULK_TIMEOUT — на весь запрос.
+// @Tags         orders
+// @Accept       application/x-ndjson
+// @Produce      application/x-ndjson
+// @Param        orders  body  entity.OrderInfo  true  "Orders, one JSON object per line"
+// @Success      200  {object}  LineResult  "one result per line"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders:bulk [post]
+func New(log *zap.Logger, uc OrdersPoster, batchTimeout time.Duration) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "BulkHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) забираем request_id
+		ctx := r.Context()
+		logger := baselog
+
+		// 2) оборачиваем логгер
+		if reqID, ok := ctx.Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+			logger = logger.With(zap.String("request_id", reqID))
+		}
+
+		// 3) читаем тело и пишем ответ одновременно; дедлайны соединения
+		// продлеваются на каждой пачке, иначе их ограничил бы HTTP_TIMEOUT сервера
+		rc := http.NewResponseController(w)
+		if err := rc.EnableFullDuplex(); err != nil {
+			logger.Debug("full duplex is not supported", zap.Error(err))
+		}
+		extend := func() {
+			deadline := time.Now().Add(batchTimeout)
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline)
+		}
+		extend()
+
+		w.Header().Set("Content-Type", ContentTypeNDJSON)
+		w.WriteHeader(http.StatusOK)
+
+		// тело читает отдельная горутина: пока сохраняется пачка, следующие
+		// строки уже копятся; тело нельзя читать после выхода из хендлера
+		lines := make(chan bodyLine, maxBatchSize)
+		stop := make(chan struct{})
+		var (
+			total   int
+			readErr error
+		)
+		readDone := make(chan struct{})
+		go func() {
+			defer close(readDone)
+			defer close(lines)
+			total, readErr = readLines(r.Body, lines, stop)
+		}()
+		defer func() {
+			close(stop)
+			_ = rc.SetReadDeadline(time.Now())
+			<-readDone
+		}()
+
+		enc := json.NewEncoder(w)
+		counts := map[string]int{}
+		for batch := range batches(ctx, lines) {
+			// 4) сохраняем заказы пачки
+			results := addBatch(ctx, uc, batch, batchTimeout)
+			if ctx.Err() != nil {
+				break
+			}
+
+			// 5) отдаём результаты строк клиенту
+			for _, res := range results {
+				counts[res.Result]++
+				if res.Result == ResultFailed {
+					logger.Error("failed to add order", zap.Int("line", res.Line), zap.String("order_uid", res.OrderUID), zap.String("reason", res.Reason))
+				}
+				if err := enc.Encode(res); err != nil {
+					logger.Warn("error sending the response", zap.Int("line", res.Line), zap.Error(err))
+
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				logger.Warn("error flushing the response", zap.Error(err))
+
+				return
+			}
+			extend()
+		}
+		if ctx.Err() != nil {
+			logger.Warn("bulk request cancelled", zap.Error(ctx.Err()))
+
+			return
+		}
+
+		<-readDone
+		switch {
+		case errors.Is(readErr, bufio.ErrTooLong):
+			// границу следующей строки не найти — дальше читать нельзя
+			_ = enc.Encode(LineResult{Line: total + 1, Result: ResultInvalid, Reason: "line exceeds 1MB"})
+		case readErr != nil:
+			logger.Warn("failed to read request body", zap.Int("lines", total), zap.Error(readErr))
+		}
+
+		logger.Info("bulk request completed",
+			zap.Int("lines", total),
+			zap.Int("inserted", counts[ResultInserted]),
+			zap.Int("duplicate", counts[ResultDuplicate]),
+			zap.Int("invalid", counts[ResultInvalid]),
+			zap.Int("failed", counts[ResultFailed]),
+		)
+	}
+}
+
+// readLines отправляет непустые строки тела в out до конца тела или stop.
+// Возвращает число прочитанных строк и ошибку чтения.
+func readLines(body io.Reader, out chan<- bodyLine, stop <-chan struct{}) (int, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		select {
+		case out <- bodyLine{num: line, raw: bytes.Clone(raw)}:
+		case <-stop:
+			return line, nil
+		}
+	}
+	return line, scanner.Err()
+}
+
+// batches собирает пачки из строк, уже пришедших от клиента: ждёт только
+// первую строку пачки, поэтому медленный клиент получает ответ на каждую строку.
+func batches(ctx context.Context, lines <-chan bodyLine) iter.Seq[[]bodyLine] {
+	return func(yield func([]bodyLine) bool) {
+		for {
+			var first bodyLine
+			select {
+			case l, ok := <-lines:
+				if !ok {
+					return
+				}
+				first = l
+			case <-ctx.Done():
+				return
+			}
+
+			batch := []bodyLine{first}
+			closed := false
+		fill:
+			for len(batch) < maxBatchSize {
+				select {
+				case l, ok := <-lines:
+					if !ok {
+						closed = true
+						break fill
+					}
+					batch = append(batch, l)
+				default:
+					break fill
+				}
+			}
+			if !yield(batch) || closed {
+				return
+			}
+		}
+	}
+}
+
+// addBatch разбирает строки пачки и сохраняет валидные по JSON заказы одним
+// вызовом AddOrders. Результаты идут в порядке строк.
+func addBatch(ctx context.Context, uc OrdersPoster, batch []bodyLine, timeout time.Duration) []LineResult {
+	results := make([]LineResult, len(batch))
+	orders := make([]*entity.OrderInfo, 0, len(batch))
+	idx := make([]int, 0, len(batch))
+	for i, l := range batch {
+		order, res, ok := decodeLine(l.raw)
+		results[i] = res
+		results[i].Line = l.num
+		if ok {
+			orders = append(orders, order)
+			idx = append(idx, i)
+		}
+	}
+	if len(orders) == 0 {
+		return results
+	}
+
+	batchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for j, res := range uc.AddOrders(batchCtx, orders) {
+		i := idx[j]
+		var uid string
+		if orders[j] != nil {
+			uid = orders[j].OrderUID
+		}
+		results[i] = lineResult(batchCtx, uid, res)
+		results[i].Line = batch[i].num
+	}
+	return results
+}
+
+// decodeLine разбирает строку так же строго, как POST /order/{order_uid}.
+// ok == false — строка невалидна, и res уже содержит причину.
+func decodeLine(raw []byte) (order *entity.OrderInfo, res LineResult, ok bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&order); err != nil {
+		if verr := problem.DecodeViolation(err); verr != nil {
+			return nil, invalid("", verr), false
+		}
+		return nil, LineResult{Result: ResultInvalid, Reason: "invalid JSON: " + err.Error()}, false
+	}
+	if dec.More() {
+		return nil, LineResult{Result: ResultInvalid, Reason: "unexpected data after JSON object"}, false
+	}
+	return order, LineResult{}, true
+}
+
+// lineResult переводит итог AddOrders в строку ответа.
+func lineResult(ctx context.Context, uid string, res entity.OrderResult) LineResult {
+	var verr *entity.ValidationError
+	switch {
+	case res.Outcome == entity.OutcomeInserted:
+		return LineResult{OrderUID: uid, Result: ResultInserted}
+	case errors.As(res.Err, &verr):
+		return invalid(uid, verr)
+	case res.Outcome == entity.OutcomeAlreadyExists:
+		return LineResult{OrderUID: uid, Result: ResultDuplicate}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return LineResult{OrderUID: uid, Result: ResultFailed, Reason: "request took longer than the timelimit"}
+	default:
+		return LineResult{OrderUID: uid, Result: ResultFailed, Reason: "unexpected internal error"}
+	}
+}
+
+func invalid(uid string, verr *entity.ValidationError) LineResult {
+	reason := "order has 1 violation"
+	if n := len(verr.Violations); n != 1 {
+		reason = "order has " + strconv.Itoa(n) + " violations"
+	}
+	return LineResult{OrderUID: uid, Result: ResultInvalid, Reason: reason, Errors: verr.Violations}
+}
@@ -0,0 +1,94 @@
+package cachehandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// 1) DELETE /admin/cache
+// 2) DELETE /admin/cache/<order_uid>
+
+type CachePurger interface {
+	PurgeCache(ctx context.Context)
+}
+
+type OrderEvicter interface {
+	EvictOrder(ctx context.Context, orderUID string) (bool, error)
+}
+
+// Purge cache
+// @Summary      Purge cache
+// @Description  Очищает кэш заказов и кэш поиска по вторичным ключам.
+// @Tags         admin
+// @Success      204
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/cache [delete]
+func NewPurge(log *zap.Logger, uc CachePurger) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "CachePurgeHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) забираем request_id
+		ctx := r.Context()
+		logger := baselog
+		// 2) оборачиваем логгер
+		if reqID, ok := ctx.Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+			logger = logger.With(zap.String("request_id", reqID))
+		}
+
+		uc.PurgeCache(ctx)
+		logger.Warn("cache purged by admin request")
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Evict order from cache
+// @Summary      Evict order from cache
+// @Description  Удаляет заказ из кэша; следующий GET прочитает его из БД.
+// @Tags         admin
+// @Param        order_uid  path  string  true  "Order UID"
+// @Success      204
+// @Failure      400  {object}  response.ErrorBody  "invalid order_uid"
+// @Failure      404  {object}  response.ErrorBody  "order is not cached"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/cache/{order_uid} [delete]
+func NewEvict(log *zap.Logger, uc OrderEvicter) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "CacheEvictHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) забираем request_id
+		ctx := r.Context()
+		logger := baselog
+		// 2) оборачиваем логгер
+		if reqID, ok := ctx.Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+			logger = logger.With(zap.String("request_id", reqID))
+		}
+
+		orderUID := chi.URLParam(r, "order_uid")
+		removed, err := uc.EvictOrder(ctx, orderUID)
+		if err != nil {
+			logger.Warn("invalid order_uid", zap.String("order_uid", orderUID))
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "invalid order_uid")
+
+			return
+		}
+		if !removed {
+			response.Error(w, r, logger, http.StatusNotFound, response.CodeNotFound, "order is not cached")
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
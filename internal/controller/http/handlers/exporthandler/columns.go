@@ -0,0 +1,108 @@
+package exporthandler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+)
+
+// column — колонка CSV: значение берётся из заказа и позиции строки.
+// Для заказа без позиций it — пустая позиция.
+type column struct {
+	name  string
+	value func(o *entity.OrderResponse, it *entity.ItemPublic) string
+}
+
+func itoa(v int64) string { return strconv.FormatInt(v, 10) }
+
+// columns — все колонки выгрузки в порядке по умолчанию.
+var columns = []column{
+	{"order_uid", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.OrderUID }},
+	{"date_created", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.DateCreated.Format(time.RFC3339) }},
+	{"status", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return string(o.Status) }},
+	{"locale", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Locale }},
+	{"track_number", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Logistics.TrackNumber }},
+	{"delivery_service", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Logistics.DeliveryService }},
+
+	{"delivery.name", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Delivery.Name }},
+	{"delivery.phone", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Delivery.Phone }},
+	{"delivery.email", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Delivery.Email }},
+	{"delivery.city", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Delivery.City }},
+	{"delivery.region", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Delivery.Region }},
+	{"delivery.address", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Delivery.Address }},
+
+	{"payment.amount", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return itoa(o.Payment.Amount) }},
+	{"payment.currency", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return o.Payment.Currency }},
+	{"payment.delivery_cost", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return itoa(o.Payment.DeliveryCost) }},
+	{"payment.goods_total", func(o *entity.OrderResponse, _ *entity.ItemPublic) string { return itoa(o.Payment.GoodsTotal) }},
+
+	{"item.chrt_id", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return optional(it.ChrtID, it) }},
+	{"item.name", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return it.Name }},
+	{"item.brand", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return it.Brand }},
+	{"item.size", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return it.Size }},
+	{"item.price", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return optional(it.Price, it) }},
+	{"item.total_price", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return optional(it.TotalPrice, it) }},
+	{"item.status", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return optional(it.Status, it) }},
+	{"item.state", func(_ *entity.OrderResponse, it *entity.ItemPublic) string { return string(it.State) }},
+}
+
+// optional оставляет числовые колонки позиции пустыми у заказа без позиций.
+func optional(v int64, it *entity.ItemPublic) string {
+	if it.ChrtID == 0 {
+		return ""
+	}
+	return itoa(v)
+}
+
+// parseColumns разбирает параметр columns; пустой — все колонки.
+func parseColumns(param string) ([]column, error) {
+	if param == "" {
+		return columns, nil
+	}
+
+	byName := make(map[string]column, len(columns))
+	for _, c := range columns {
+		byName[c.name] = c
+	}
+
+	var out []column
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column: %q", name)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// header — первая строка CSV.
+func header(cols []column) []string {
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = c.name
+	}
+	return out
+}
+
+// records раскладывает заказ в строки CSV, по одной на позицию.
+func records(cols []column, o *entity.OrderResponse) [][]string {
+	items := o.Items
+	if len(items) == 0 {
+		items = []entity.ItemPublic{{}}
+	}
+
+	out := make([][]string, 0, len(items))
+	for i := range items {
+		rec := make([]string, len(cols))
+		for j, c := range cols {
+			rec[j] = c.value(o, &items[i])
+		}
+		out = append(out, rec)
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package exporthandler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/searchhandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/pii"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// GET /orders/export?format=csv|ndjson&columns=...
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+
+	ContentTypeCSV    = "text/csv; charset=utf-8"
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// flushEvery — через сколько заказов ответ сбрасывается клиенту
+	flushEvery = 100
+)
+
+type OrdersExporter interface {
+	ExportOrders(ctx context.Context, filter entity.OrderFilter, fn func(*entity.OrderResponse) error) error
+}
+
+// Export orders
+// @Summary      Export orders
+// @Description  Выгружает все заказы под фильтр (те же параметры, что у GET /orders, кроме limit и cursor)
+// @Description  потоком из курсора PostgreSQL. CSV — строка на позицию заказа, набор и порядок колонок
+// @Description  задаются параметром columns (по умолчанию все); NDJSON — заказ на строку.
+// @Description  Ошибка после начала выгрузки обрывает ответ: статус уже отправлен.
+// @Description  Персональные данные получателя маскируются так же, как в GET /order/{order_uid}.
+// @Tags         orders
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        format            query  string  false  "Export format"  Enums(csv, ndjson)  default(csv)
+// @Param        columns           query  string  false  "CSV columns, comma separated (e.g. order_uid,date_created,item.name,item.price)"
+// @Param        customer_id       query  string  false  "Customer ID"
+// @Param        track_number      query  string  false  "Track number"
+// @Param        delivery_service  query  string  false  "Delivery service"
+// @Param        date_from         query  string  false  "date_created >= (RFC3339)"
+// @Param        date_to           query  string  false  "date_created < (RFC3339)"
+// @Param        payment_provider  query  string  false  "Payment provider"
+// @Param        payment_bank      query  string  false  "Payment bank"
+// @Param        nm_id             query  int     false  "Item nm_id"
+// @Param        brand             query  string  false  "Item brand"
+// @Success      200  {string}  string              "CSV or NDJSON stream"
+// @Failure      400  {object}  response.ErrorBody  "invalid query parameter"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/export [get]
+func New(log *zap.Logger, uc OrdersExporter, masker *pii.Masker, writeTimeout time.Duration) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "ExportHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) забираем request_id
+		ctx := r.Context()
+		logger := baselog
+
+		// 2) оборачиваем логгер
+		if reqID, ok := ctx.Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+			logger = logger.With(zap.String("request_id", reqID))
+		}
+
+		// 3) разбираем фильтры и формат
+		filter, err := searchhandler.ParseFilter(r)
+		if err != nil {
+			logger.Warn("invalid export query", zap.String("query", r.URL.RawQuery), zap.Error(err))
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, err.Error())
+
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = FormatCSV
+		}
+		if format != FormatCSV && format != FormatNDJSON {
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "format must be csv or ndjson")
+
+			return
+		}
+		cols, err := parseColumns(r.URL.Query().Get("columns"))
+		if err != nil {
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, err.Error())
+
+			return
+		}
+
+		// 4) заголовки отправляются с первым заказом: до него ещё можно ответить ошибкой
+		rc := http.NewResponseController(w)
+		cw := csv.NewWriter(w)
+		enc := json.NewEncoder(w)
+		started := false
+		start := func() {
+			if started {
+				return
+			}
+			started = true
+			w.Header().Set("Content-Disposition", `attachment; filename="orders.`+format+`"`)
+			if format == FormatCSV {
+				w.Header().Set("Content-Type", ContentTypeCSV)
+				w.WriteHeader(http.StatusOK)
+				_ = cw.Write(header(cols))
+
+				return
+			}
+			w.Header().Set("Content-Type", ContentTypeNDJSON)
+			w.WriteHeader(http.StatusOK)
+		}
+		write := func(o *entity.OrderResponse) error {
+			start()
+			if format == FormatNDJSON {
+				return enc.Encode(o)
+			}
+			for _, rec := range records(cols, o) {
+				if err := cw.Write(rec); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		flush := func() error {
+			start()
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+
+		// 5) выгружаем; дедлайн записи продлевается на каждой порции,
+		// иначе длинную выгрузку оборвал бы HTTP_TIMEOUT сервера
+		written := 0
+		_ = rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+		err = uc.ExportOrders(ctx, filter, func(o *entity.OrderResponse) error {
+			if err := write(masker.Order(ctx, o)); err != nil {
+				return err
+			}
+			written++
+			if written%flushEvery != 0 {
+				return nil
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+			return rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+		})
+
+		// 6) ошибка до первого заказа — обычный ответ об ошибке, после — обрыв потока
+		if err != nil {
+			if started {
+				logger.Warn("export aborted", zap.Int("orders", written), zap.Error(err))
+
+				return
+			}
+			switch {
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				logger.Error("timeout exceeded", zap.Error(err))
+				response.Error(w, r, logger, http.StatusGatewayTimeout, response.CodeTimeout, "request took longer than the timelimit")
+			case errors.Is(err, entity.ErrInvalidInput):
+				response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "invalid filter")
+			default:
+				logger.Error("failed to export orders", zap.Error(err))
+				response.Error(w, r, logger, http.StatusInternalServerError, response.CodeInternal, "unexpected internal error")
+			}
+
+			return
+		}
+
+		if err := flush(); err != nil {
+			logger.Warn("error sending the response", zap.Error(err))
+
+			return
+		}
+		logger.Info("export completed", zap.String("format", format), zap.Int("orders", written))
+	}
+}
@@ -0,0 +1,60 @@
+package healthhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/health"
+	"go.uber.org/zap"
+)
+
+type ReadinessChecker interface {
+	Ready(ctx context.Context) health.Report
+}
+
+type LivenessResponse struct {
+	Status string `json:"status"`
+}
+
+// Liveness
+// @Summary      Liveness probe
+// @Description  Процесс жив и обслуживает HTTP.
+// @Tags         service
+// @Produce      json
+// @Success      200  {object}  LivenessResponse
+// @Router       /healthz [get]
+func Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, LivenessResponse{Status: health.StatusUp})
+	}
+}
+
+// Readiness
+// @Summary      Readiness probe
+// @Description  Проверяет PostgreSQL, Kafka, версию миграций и прогрев кэша. Во время graceful shutdown возвращает 503.
+// @Tags         service
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Failure      503  {object}  health.Report
+// @Router       /readyz [get]
+func Readiness(log *zap.Logger, checker ReadinessChecker) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "ReadinessHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Ready(r.Context())
+
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+			baselog.Warn("service is not ready", zap.Any("components", report.Components))
+		}
+		writeJSON(w, status, report)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,179 @@
+package lookuphandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/pii"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// 1) GET /orders/by-track/{track_number}
+// 2) GET /orders/by-transaction/{transaction}
+// 3) GET /customers/{customer_id}/orders?limit=...&cursor=...
+
+type OrdersByTrackGetter interface {
+	GetOrdersByTrack(ctx context.Context, trackNumber string) (*entity.OrderListResponse, error)
+}
+
+type OrderByTransactionGetter interface {
+	GetOrderByTransaction(ctx context.Context, transaction string) (*entity.OrderResponse, error)
+}
+
+type CustomerOrdersGetter interface {
+	GetCustomerOrders(ctx context.Context, customerID string, limit int, after *entity.OrderCursor) (*entity.OrderListResponse, error)
+}
+
+// Get orders by track number
+// @Summary      Get orders by track number
+// @Description  Возвращает заказы с данным track_number (новые первыми).
+// @Tags         orders
+// @Produce      json
+// @Param        track_number  path  string  true  "Track number"
+// @Success      200  {object}  entity.OrderListResponse
+// @Failure      404  {object}  response.ErrorBody  "order not found"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/by-track/{track_number} [get]
+func NewByTrack(log *zap.Logger, uc OrdersByTrackGetter, masker *pii.Masker) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "ByTrackHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := requestLogger(ctx, baselog)
+
+		trackNumber := chi.URLParam(r, "track_number")
+		orders, err := uc.GetOrdersByTrack(ctx, trackNumber)
+		if err != nil {
+			writeError(w, r, logger, err)
+
+			return
+		}
+
+		response.JSON(w, r, logger, http.StatusOK, masker.List(ctx, orders))
+	}
+}
+
+// Get order by payment transaction
+// @Summary      Get order by payment transaction
+// @Description  Возвращает заказ по payment.transaction.
+// @Tags         orders
+// @Produce      json
+// @Param        transaction  path  string  true  "Payment transaction"
+// @Success      200  {object}  entity.OrderResponse
+// @Failure      404  {object}  response.ErrorBody  "order not found"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/by-transaction/{transaction} [get]
+func NewByTransaction(log *zap.Logger, uc OrderByTransactionGetter, masker *pii.Masker) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "ByTransactionHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := requestLogger(ctx, baselog)
+
+		transaction := chi.URLParam(r, "transaction")
+		order, err := uc.GetOrderByTransaction(ctx, transaction)
+		if err != nil {
+			writeError(w, r, logger, err)
+
+			return
+		}
+
+		response.JSON(w, r, logger, http.StatusOK, masker.Order(ctx, order))
+	}
+}
+
+// Get customer orders
+// @Summary      Get customer orders
+// @Description  Возвращает заказы покупателя постранично (created_at DESC, order_uid DESC).
+// @Tags         orders
+// @Produce      json
+// @Param        customer_id  path   string  true   "Customer ID"
+// @Param        limit        query  int     false  "Page size (default 50, max 500)"
+// @Param        cursor       query  string  false  "next_cursor from previous page"
+// @Success      200  {object}  entity.OrderListResponse
+// @Failure      400  {object}  response.ErrorBody  "invalid query parameter"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /customers/{customer_id}/orders [get]
+func NewByCustomer(log *zap.Logger, uc CustomerOrdersGetter, masker *pii.Masker) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "ByCustomerHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := requestLogger(ctx, baselog)
+
+		var (
+			limit int
+			after *entity.OrderCursor
+			err   error
+		)
+		if v := r.URL.Query().Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+				logger.Warn("invalid limit", zap.String("limit", v))
+				response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "invalid limit")
+
+				return
+			}
+		}
+		if v := r.URL.Query().Get("cursor"); v != "" {
+			if after, err = entity.DecodeOrderCursor(v); err != nil {
+				logger.Warn("invalid cursor", zap.String("cursor", v))
+				response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, err.Error())
+
+				return
+			}
+		}
+
+		customerID := chi.URLParam(r, "customer_id")
+		orders, err := uc.GetCustomerOrders(ctx, customerID, limit, after)
+		if err != nil {
+			writeError(w, r, logger, err)
+
+			return
+		}
+
+		response.JSON(w, r, logger, http.StatusOK, masker.List(ctx, orders))
+	}
+}
+
+func requestLogger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if reqID, ok := ctx.Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+		return logger.With(zap.String("request_id", reqID))
+	}
+
+	return logger
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, logger *zap.Logger, err error) {
+	switch {
+	case errors.Is(r.Context().Err(), context.DeadlineExceeded):
+		logger.Error("timeout exceeded", zap.Error(err))
+		response.Error(w, r, logger, http.StatusGatewayTimeout, response.CodeTimeout, "request took longer than the timelimit")
+	case errors.Is(err, entity.ErrInvalidInput):
+		response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "invalid lookup key")
+	case errors.Is(err, entity.ErrorOrderNotFound):
+		response.Error(w, r, logger, http.StatusNotFound, response.CodeNotFound, "order not found")
+	default:
+		logger.Error("failed to lookup orders", zap.Error(err))
+		response.Error(w, r, logger, http.StatusInternalServerError, response.CodeInternal, "unexpected internal error")
+	}
+}
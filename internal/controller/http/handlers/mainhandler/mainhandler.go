@@ -2,11 +2,12 @@ package mainhandler
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/pii"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -21,15 +22,23 @@ type OrderInfoGetter interface {
 // Get Order by UID
 // @Summary      Get order by UID
 // @Description  Возвращает информацию о заказе по order_uid.
+// @Description  При Accept: text/html заказ и ошибки отдаются HTML-страницей.
+// @Description  Имя, телефон, email и адрес получателя маскируются, если роль клиента ниже PII_UNMASKED_ROLE.
 // @Tags         orders
+// @Produce      json
+// @Produce      html
 // @Param        order_uid   path      string  true  "Order UID"
 // @Success      200  {object}  entity.OrderResponse
-// @Failure      400  {object}  APIError  "invalid order_uid"
-// @Failure      404  {object}  APIError  "order not found"
-// @Failure      504  {object}  APIError  "timeout exceeded"
-// @Failure      500  {object}  APIError  "unexpected internal error"
+// @Failure      400  {object}  response.ErrorBody  "invalid order_uid"
+// @Failure      404  {object}  response.ErrorBody  "order not found"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /order/{order_uid} [get]
-func New(log *zap.Logger, uc OrderInfoGetter) http.HandlerFunc {
+func New(log *zap.Logger, uc OrderInfoGetter, masker *pii.Masker) http.HandlerFunc {
 	baselog := log.With(zap.String("handler", "MainHandler"))
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -44,12 +53,9 @@ func New(log *zap.Logger, uc OrderInfoGetter) http.HandlerFunc {
 
 		// 3) достаем UID из URL
 		orderUID := chi.URLParam(r, "order_uid")
-		if err := uidParser(orderUID); err != nil {
+		if err := ValidateUID(orderUID); err != nil {
 			logger.Warn("invalid order_uid", zap.String("order_uid", orderUID))
-			errDTO := APIError{
-				Message: err.Error(),
-			}
-			http.Error(w, errDTO.Message, http.StatusBadRequest)
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, err.Error())
 
 			return
 		}
@@ -60,46 +66,28 @@ func New(log *zap.Logger, uc OrderInfoGetter) http.HandlerFunc {
 			switch {
 			case errors.Is(ctx.Err(), context.DeadlineExceeded):
 				logger.Error("timeout exceeded", zap.Error(err))
-				errDTO := APIError{
-					Message: "request took longer than the timelimit",
-				}
-				http.Error(w, errDTO.Message, http.StatusGatewayTimeout)
+				response.Error(w, r, logger, http.StatusGatewayTimeout, response.CodeTimeout, "request took longer than the timelimit")
 
 				return
 			case errors.Is(err, entity.ErrorOrderNotFound):
 				logger.Info("order not found", zap.String("order_uid", orderUID))
-				errDTO := APIError{
-					Message: "order not found",
-				}
-				http.Error(w, errDTO.Message, http.StatusNotFound)
+				response.Error(w, r, logger, http.StatusNotFound, response.CodeNotFound, "order not found")
 
 				return
 			}
 			logger.Error("failed to get order", zap.Error(err))
-			errDTO := APIError{
-				Message: "unexpected internal error",
-			}
-			http.Error(w, errDTO.Message, http.StatusInternalServerError)
+			response.Error(w, r, logger, http.StatusInternalServerError, response.CodeInternal, "unexpected internal error")
 
 			return
 		}
 
-		// 5) формируем успешный ответ
-		b, err := json.MarshalIndent(order, "", "	")
-		if err != nil {
-			logger.Error("error marshal response")
-		}
-
-		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write(b); err != nil {
-			logger.Error("error sending the response")
-
-			return
-		}
+		// 5) формируем успешный ответ, персональные данные — по роли клиента
+		response.JSON(w, r, logger, http.StatusOK, masker.Order(ctx, order))
 	}
 }
 
-func uidParser(uid string) error {
+// ValidateUID проверяет order_uid из пути запроса.
+func ValidateUID(uid string) error {
 	if len(uid) != 0 && uid == strings.ToLower(uid) {
 		return nil
 	}
@@ -0,0 +1,135 @@
+package searchhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/pii"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// GET /orders?customer_id=...&cursor=...
+
+type OrdersSearcher interface {
+	SearchOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderListResponse, error)
+}
+
+// Search orders
+// @Summary      Search orders
+// @Description  Поиск заказов по фильтрам с keyset-пагинацией (created_at DESC, order_uid DESC).
+// @Tags         orders
+// @Produce      json
+// @Param        customer_id       query  string  false  "Customer ID"
+// @Param        track_number      query  string  false  "Track number"
+// @Param        delivery_service  query  string  false  "Delivery service"
+// @Param        date_from         query  string  false  "date_created >= (RFC3339)"
+// @Param        date_to           query  string  false  "date_created < (RFC3339)"
+// @Param        payment_provider  query  string  false  "Payment provider"
+// @Param        payment_bank      query  string  false  "Payment bank"
+// @Param        nm_id             query  int     false  "Item nm_id"
+// @Param        brand             query  string  false  "Item brand"
+// @Param        limit             query  int     false  "Page size (default 50, max 500)"
+// @Param        cursor            query  string  false  "next_cursor from previous page"
+// @Success      200  {object}  entity.OrderListResponse
+// @Failure      400  {object}  response.ErrorBody  "invalid query parameter"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders [get]
+func New(log *zap.Logger, uc OrdersSearcher, masker *pii.Masker) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "SearchHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) забираем request_id
+		ctx := r.Context()
+		logger := baselog
+
+		// 2) оборачиваем логгер
+		if reqID, ok := ctx.Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+			logger = logger.With(zap.String("request_id", reqID))
+		}
+
+		// 3) разбираем фильтры
+		filter, err := ParseFilter(r)
+		if err != nil {
+			logger.Warn("invalid search query", zap.String("query", r.URL.RawQuery), zap.Error(err))
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, err.Error())
+
+			return
+		}
+
+		// 4) вызываем usecase
+		page, err := uc.SearchOrders(ctx, filter)
+		if err != nil {
+			switch {
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				logger.Error("timeout exceeded", zap.Error(err))
+				response.Error(w, r, logger, http.StatusGatewayTimeout, response.CodeTimeout, "request took longer than the timelimit")
+
+				return
+			case errors.Is(err, entity.ErrInvalidInput):
+				response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "invalid filter")
+
+				return
+			}
+			logger.Error("failed to search orders", zap.Error(err))
+			response.Error(w, r, logger, http.StatusInternalServerError, response.CodeInternal, "unexpected internal error")
+
+			return
+		}
+
+		// 5) формируем успешный ответ
+		response.JSON(w, r, logger, http.StatusOK, masker.List(ctx, page))
+	}
+}
+
+// ParseFilter разбирает фильтры поиска из query-параметров запроса.
+func ParseFilter(r *http.Request) (entity.OrderFilter, error) {
+	q := r.URL.Query()
+	f := entity.OrderFilter{
+		CustomerID:      q.Get("customer_id"),
+		TrackNumber:     q.Get("track_number"),
+		DeliveryService: q.Get("delivery_service"),
+		PaymentProvider: q.Get("payment_provider"),
+		PaymentBank:     q.Get("payment_bank"),
+		ItemBrand:       q.Get("brand"),
+	}
+
+	var err error
+	if v := q.Get("date_from"); v != "" {
+		if f.DateFrom, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, fmt.Errorf("invalid date_from: %q", v)
+		}
+	}
+	if v := q.Get("date_to"); v != "" {
+		if f.DateTo, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, fmt.Errorf("invalid date_to: %q", v)
+		}
+	}
+	if v := q.Get("nm_id"); v != "" {
+		if f.ItemNmID, err = strconv.ParseInt(v, 10, 64); err != nil || f.ItemNmID <= 0 {
+			return f, fmt.Errorf("invalid nm_id: %q", v)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit <= 0 {
+			return f, fmt.Errorf("invalid limit: %q", v)
+		}
+	}
+	if v := q.Get("cursor"); v != "" {
+		if f.After, err = entity.DecodeOrderCursor(v); err != nil {
+			return f, err
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,129 @@
+package statushandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/mainhandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// PATCH /order/<order_uid>/status
+
+type OrderStatusUpdater interface {
+	UpdateOrderStatus(ctx context.Context, upd *entity.StatusUpdate, source string) (*entity.StatusChange, error)
+}
+
+type UpdateStatusRequest struct {
+	Status entity.OrderStatus `json:"status" example:"paid"`
+	ChrtID int64              `json:"chrt_id,omitempty"`
+	Reason string             `json:"reason,omitempty"`
+}
+
+// Update order status
+// @Summary      Update order status
+// @Description  Переводит заказ (или позицию, если передан chrt_id) в новый статус.
+// @Description  Переходы: created→paid|cancelled, paid→assembled|cancelled, assembled→shipped|cancelled, shipped→delivered|returned, delivered→returned.
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        order_uid  path  string               true  "Order UID"
+// @Param        request    body  UpdateStatusRequest  true  "New status"
+// @Success      200  {object}  entity.StatusChange
+// @Failure      400  {object}  response.ErrorBody  "invalid request"
+// @Failure      404  {object}  response.ErrorBody  "order not found"
+// @Failure      409  {object}  response.ErrorBody  "transition not allowed"
+// @Failure      504  {object}  response.ErrorBody  "timeout exceeded"
+// @Failure      500  {object}  response.ErrorBody  "unexpected internal error"
+// @Failure      401  {object}  response.ErrorBody  "missing or invalid credentials"
+// @Failure      403  {object}  response.ErrorBody  "role is not allowed"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /order/{order_uid}/status [patch]
+func New(log *zap.Logger, uc OrderStatusUpdater) http.HandlerFunc {
+	baselog := log.With(zap.String("handler", "StatusHandler"))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) забираем request_id
+		ctx := r.Context()
+		logger := baselog
+
+		// 2) оборачиваем логгер
+		if reqID, ok := ctx.Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+			logger = logger.With(zap.String("request_id", reqID))
+		}
+
+		// 3) достаем UID из URL
+		orderUID := chi.URLParam(r, "order_uid")
+		if err := mainhandler.ValidateUID(orderUID); err != nil {
+			logger.Warn("invalid order_uid", zap.String("order_uid", orderUID))
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, err.Error())
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<16)
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				logger.Error("failed to close body:", zap.Error(err))
+			}
+		}()
+
+		// 4) достаем JSON
+		var req UpdateStatusRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				response.Error(w, r, logger, http.StatusBadRequest, response.CodeMalformedBody, "empty body")
+
+				return
+			}
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeMalformedBody, "invalid JSON: "+err.Error())
+
+			return
+		}
+		if !req.Status.Valid() {
+			response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "unknown status")
+
+			return
+		}
+
+		// 5) вызываем usecase
+		change, err := uc.UpdateOrderStatus(ctx, &entity.StatusUpdate{
+			OrderUID: orderUID,
+			ChrtID:   req.ChrtID,
+			Status:   req.Status,
+			Reason:   req.Reason,
+		}, entity.StatusSourceHTTP)
+		if err != nil {
+			switch {
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				logger.Error("timeout exceeded", zap.Error(err))
+				response.Error(w, r, logger, http.StatusGatewayTimeout, response.CodeTimeout, "request took longer than the timelimit")
+			case errors.Is(err, entity.ErrInvalidInput):
+				response.Error(w, r, logger, http.StatusBadRequest, response.CodeInvalidArgument, "invalid status update")
+			case errors.Is(err, entity.ErrorOrderNotFound):
+				response.Error(w, r, logger, http.StatusNotFound, response.CodeNotFound, "order not found")
+			case errors.Is(err, entity.ErrInvalidTransition):
+				response.Error(w, r, logger, http.StatusConflict, response.CodeConflict, "transition not allowed")
+			case errors.Is(err, entity.ErrStatusConflict):
+				response.Error(w, r, logger, http.StatusConflict, response.CodeConflict, "status was changed concurrently, retry")
+			default:
+				logger.Error("failed to update status", zap.Error(err))
+				response.Error(w, r, logger, http.StatusInternalServerError, response.CodeInternal, "unexpected internal error")
+			}
+
+			return
+		}
+
+		// 6) формируем успешный ответ
+		response.JSON(w, r, logger, http.StatusOK, change)
+	}
+}
@@ -0,0 +1,304 @@
+package custommiddleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// Role — уровень доступа к API. Роли вложены: writer может всё, что reader,
+// admin — всё, что writer.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleReader
+	RoleWriter
+	RoleAdmin
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// minSecretLen — минимальная длина HMAC-секрета (256 бит для HS256).
+const minSecretLen = 32
+
+var (
+	errNoCredentials  = errors.New("credentials required")
+	errBadCredentials = errors.New("invalid credentials")
+)
+
+// ParseRole разбирает имя роли из конфигурации или claim токена.
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "reader":
+		return RoleReader, nil
+	case "writer":
+		return RoleWriter, nil
+	case "admin":
+		return RoleAdmin, nil
+	}
+	return RoleNone, fmt.Errorf("unknown role %q", s)
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleReader:
+		return "reader"
+	case RoleWriter:
+		return "writer"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// Principal — аутентифицированный клиент запроса.
+type Principal struct {
+	Subject string
+	Role    Role
+	// Method — чем клиент аутентифицирован: api_key, jwt или anonymous
+	Method string
+}
+
+type principalKey struct{}
+
+// PrincipalFrom возвращает клиента запроса, прошедшего Require.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Claims — claims JWT: стандартные плюс роль.
+type Claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type apiKey struct {
+	key  []byte
+	role Role
+	// id — отпечаток ключа для логов, сам ключ не логируется
+	id string
+}
+
+// Authenticator проверяет статические API-ключи (заголовок X-API-Key) и
+// JWT с HMAC-подписью (Authorization: Bearer) локально, без внешнего IdP.
+type Authenticator struct {
+	log       *zap.Logger
+	disabled  bool
+	keys      []apiKey
+	secret    []byte
+	issuer    string
+	audience  string
+	leeway    time.Duration
+	anonymous Role
+}
+
+// AuthOption -.
+type AuthOption func(*Authenticator) error
+
+// APIKeys задаёт статические ключи: ключ -> имя роли.
+func APIKeys(keys map[string]string) AuthOption {
+	return func(a *Authenticator) error {
+		for key, name := range keys {
+			if key == "" {
+				return errors.New("empty api key")
+			}
+			role, err := ParseRole(name)
+			if err != nil {
+				return fmt.Errorf("api key role: %w", err)
+			}
+			sum := sha256.Sum256([]byte(key))
+			a.keys = append(a.keys, apiKey{key: []byte(key), role: role, id: "key:" + hex.EncodeToString(sum[:4])})
+		}
+		return nil
+	}
+}
+
+// JWTSecret включает проверку bearer-токенов, подписанных HS256/HS384/HS512.
+func JWTSecret(secret string) AuthOption {
+	return func(a *Authenticator) error {
+		if len(secret) < minSecretLen {
+			return fmt.Errorf("jwt secret must be at least %d bytes", minSecretLen)
+		}
+		a.secret = []byte(secret)
+		return nil
+	}
+}
+
+// JWTIssuer требует совпадения claim iss.
+func JWTIssuer(issuer string) AuthOption {
+	return func(a *Authenticator) error {
+		a.issuer = issuer
+		return nil
+	}
+}
+
+// JWTAudience требует, чтобы claim aud содержал audience.
+func JWTAudience(audience string) AuthOption {
+	return func(a *Authenticator) error {
+		a.audience = audience
+		return nil
+	}
+}
+
+// AnonymousRole задаёт роль запросов без учётных данных; пустая — такие запросы отклоняются.
+func AnonymousRole(name string) AuthOption {
+	return func(a *Authenticator) error {
+		if name == "" {
+			a.anonymous = RoleNone
+			return nil
+		}
+		role, err := ParseRole(name)
+		if err != nil {
+			return fmt.Errorf("anonymous role: %w", err)
+		}
+		a.anonymous = role
+		return nil
+	}
+}
+
+// AuthDisabled отключает проверку: Require пропускает все запросы.
+func AuthDisabled() AuthOption {
+	return func(a *Authenticator) error {
+		a.disabled = true
+		return nil
+	}
+}
+
+func NewAuthenticator(log *zap.Logger, opts ...AuthOption) (*Authenticator, error) {
+	a := &Authenticator{
+		log:    log.With(zap.String("component", "middleware/auth")),
+		leeway: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case a.disabled:
+		a.log.Warn("authentication is disabled")
+	case len(a.keys) == 0 && a.secret == nil && a.anonymous == RoleNone:
+		a.log.Warn("no api keys or jwt secret configured, protected routes will reject every request")
+	}
+	return a, nil
+}
+
+// Require пропускает запрос, только если у клиента есть роль не ниже role.
+// Нет учётных данных или они неверны — 401, роли не хватает — 403.
+func (a *Authenticator) Require(role Role) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if a.disabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := a.log.With(zap.String("path", r.URL.Path))
+			if reqID, ok := r.Context().Value(entity.RequestIDKey{}).(string); ok && reqID != "" {
+				logger = logger.With(zap.String("request_id", reqID))
+			}
+
+			p, err := a.authenticate(r)
+			if err != nil {
+				logger.Warn("authentication failed", zap.Error(err))
+				if a.secret != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="orders"`)
+				}
+				// подробности разбора токена остаются в логе, клиенту — только общий ответ
+				msg := errBadCredentials.Error()
+				if errors.Is(err, errNoCredentials) {
+					msg = errNoCredentials.Error()
+				}
+				response.Error(w, r, logger, http.StatusUnauthorized, response.CodeUnauthenticated, msg)
+
+				return
+			}
+			if p.Role < role {
+				logger.Warn("access denied",
+					zap.String("subject", p.Subject),
+					zap.Stringer("role", p.Role),
+					zap.Stringer("required", role),
+				)
+				response.Error(w, r, logger, http.StatusForbidden, response.CodeForbidden, role.String()+" role required")
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+		})
+	}
+}
+
+// authenticate определяет клиента по заголовкам. Неверные учётные данные
+// не понижаются до анонимного доступа.
+func (a *Authenticator) authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return a.checkAPIKey(key)
+	}
+
+	if authz := r.Header.Get("Authorization"); authz != "" {
+		scheme, token, ok := strings.Cut(authz, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || a.secret == nil {
+			return Principal{}, errBadCredentials
+		}
+		return a.checkJWT(strings.TrimSpace(token))
+	}
+
+	if a.anonymous != RoleNone {
+		return Principal{Role: a.anonymous, Method: "anonymous"}, nil
+	}
+	return Principal{}, errNoCredentials
+}
+
+func (a *Authenticator) checkAPIKey(key string) (Principal, error) {
+	// сравниваем со всеми ключами за постоянное время, чтобы не выдавать совпадение по таймингу
+	var found *apiKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(a.keys[i].key, []byte(key)) == 1 {
+			found = &a.keys[i]
+		}
+	}
+	if found == nil {
+		return Principal{}, errBadCredentials
+	}
+	return Principal{Subject: found.id, Role: found.role, Method: "api_key"}, nil
+}
+
+func (a *Authenticator) checkJWT(raw string) (Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(a.leeway),
+	}
+	if a.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.issuer))
+	}
+	if a.audience != "" {
+		opts = append(opts, jwt.WithAudience(a.audience))
+	}
+
+	var claims Claims
+	if _, err := jwt.ParseWithClaims(raw, &claims, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	}, opts...); err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", errBadCredentials, err)
+	}
+
+	role, err := ParseRole(claims.Role)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", errBadCredentials, err)
+	}
+	return Principal{Subject: claims.Subject, Role: role, Method: "jwt"}, nil
+}
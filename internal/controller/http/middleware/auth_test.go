@@ -0,0 +1,117 @@
+package custommiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func token(t *testing.T, secret string, method jwt.SigningMethod, role string, exp time.Time) string {
+	t.Helper()
+
+	claims := Claims{Role: role, RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   "finance-bot",
+		Issuer:    "orders-test",
+		ExpiresAt: jwt.NewNumericDate(exp),
+	}}
+	s, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return s
+}
+
+func guarded(t *testing.T, role Role, opts ...AuthOption) http.Handler {
+	t.Helper()
+
+	auth, err := NewAuthenticator(zap.NewNop(), opts...)
+	require.NoError(t, err)
+	return auth.Require(role)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFrom(r.Context())
+		require.True(t, ok)
+		w.Header().Set("X-Subject", p.Subject)
+		w.Header().Set("X-Role", p.Role.String())
+	}))
+}
+
+func do(h http.Handler, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequireAPIKeys(t *testing.T) {
+	h := guarded(t, RoleWriter, APIKeys(map[string]string{"r-key": "reader", "w-key": "writer", "a-key": "admin"}))
+
+	require.Equal(t, http.StatusUnauthorized, do(h, "", "").Code)
+	require.Equal(t, http.StatusUnauthorized, do(h, HeaderAPIKey, "nope").Code)
+	require.Equal(t, http.StatusForbidden, do(h, HeaderAPIKey, "r-key").Code)
+	require.Equal(t, http.StatusOK, do(h, HeaderAPIKey, "w-key").Code)
+
+	rec := do(h, HeaderAPIKey, "a-key")
+	require.Equal(t, http.StatusOK, rec.Code, "admin includes writer")
+	require.Equal(t, "admin", rec.Header().Get("X-Role"))
+	require.Regexp(t, `^key:[0-9a-f]{8}$`, rec.Header().Get("X-Subject"), "key itself is never exposed")
+}
+
+func TestRequireJWT(t *testing.T) {
+	h := guarded(t, RoleReader, JWTSecret(testSecret), JWTIssuer("orders-test"))
+	hour := time.Now().Add(time.Hour)
+
+	rec := do(h, "Authorization", "Bearer "+token(t, testSecret, jwt.SigningMethodHS256, "reader", hour))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "finance-bot", rec.Header().Get("X-Subject"))
+
+	cases := map[string]string{
+		"expired":       token(t, testSecret, jwt.SigningMethodHS256, "reader", time.Now().Add(-time.Hour)),
+		"wrong secret":  token(t, "another-secret-another-secret-!!", jwt.SigningMethodHS256, "reader", hour),
+		"unknown role":  token(t, testSecret, jwt.SigningMethodHS512, "root", hour),
+		"not a token":   "abc.def.ghi",
+		"missing token": "",
+	}
+	for name, tok := range cases {
+		rec := do(h, "Authorization", "Bearer "+tok)
+		require.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		require.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer", name)
+		require.Contains(t, rec.Body.String(), `"message": "invalid credentials"`, name)
+		require.NotContains(t, rec.Body.String(), "token", name, "jwt parser details stay in the log")
+	}
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{Role: "admin", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(hour),
+	}}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, do(h, "Authorization", "Bearer "+none).Code, "alg none is rejected")
+}
+
+func TestRequireAnonymousAndDisabled(t *testing.T) {
+	h := guarded(t, RoleReader, AnonymousRole("reader"))
+	require.Equal(t, http.StatusOK, do(h, "", "").Code)
+	require.Equal(t, http.StatusUnauthorized, do(h, HeaderAPIKey, "bad").Code, "bad credentials are not downgraded to anonymous")
+	require.Equal(t, http.StatusForbidden, do(guarded(t, RoleWriter, AnonymousRole("reader")), "", "").Code)
+
+	auth, err := NewAuthenticator(zap.NewNop(), AuthDisabled())
+	require.NoError(t, err)
+	rec := do(auth.Require(RoleAdmin)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})), "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestNewAuthenticatorRejectsBadConfig(t *testing.T) {
+	_, err := NewAuthenticator(zap.NewNop(), APIKeys(map[string]string{"k": "superuser"}))
+	require.Error(t, err)
+
+	_, err = NewAuthenticator(zap.NewNop(), JWTSecret("short"))
+	require.Error(t, err)
+
+	_, err = NewAuthenticator(zap.NewNop(), AnonymousRole("guest"))
+	require.Error(t, err)
+}
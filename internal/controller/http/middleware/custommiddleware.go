@@ -3,14 +3,17 @@ package custommiddleware
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	prom_metrics "github.com/RozmiDan/wb_tech_testtask/internal/metrics"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 )
 
-func CustomLogger(log *zap.Logger, httpTimeout time.Duration) func(next http.Handler) http.Handler {
+func CustomLogger(log *zap.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		baselog := log.With(zap.String("component", "middleware/logger"))
 		baselog.Info("logger middleware enabled")
@@ -26,7 +29,6 @@ func CustomLogger(log *zap.Logger, httpTimeout time.Duration) func(next http.Han
 			)
 
 			ctx := context.WithValue(r.Context(), entity.RequestIDKey{}, reqID)
-			ctx, cancel := context.WithTimeout(ctx, httpTimeout)
 			t1 := time.Now()
 
 			defer func() {
@@ -34,34 +36,49 @@ func CustomLogger(log *zap.Logger, httpTimeout time.Duration) func(next http.Han
 					// zap.Int("status", ww.Status()),
 					zap.Duration("request time", time.Since(t1)),
 				)
-				cancel()
 			}()
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// func PrometheusMiddleware(next http.Handler) http.Handler {
-// 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		//path := r.URL.Path
-// 		method := r.Method
+// Timeout ограничивает время обработки запроса через контекст. Вынесен из
+// CustomLogger, чтобы потоковые ручки могли работать со своим лимитом.
+func Timeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-// 		rw := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+func PrometheusMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
 
-// 		prom_metrics.HTTPInFlight.WithLabelValues(method).Inc()
-// 		defer prom_metrics.HTTPInFlight.WithLabelValues(method).Dec()
+		rw := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-// 		histTimer := prometheus.NewTimer(prom_metrics.HTTPDuration.WithLabelValues(method))
-// 		defer func() {
-// 			histTimer.ObserveDuration()
-// 		}()
+		prom_metrics.HTTPInFlight.WithLabelValues(method).Inc()
+		defer prom_metrics.HTTPInFlight.WithLabelValues(method).Dec()
 
-// 		// timer := prometheus.NewTimer(prom_metrics.HTTPDuration.WithLabelValues(method, path))
-// 		// defer timer.ObserveDuration()
+		t1 := time.Now()
+		next.ServeHTTP(rw, r)
 
-// 		next.ServeHTTP(rw, r)
+		// шаблон маршрута известен только после роутинга; используем его, а не path,
+		// чтобы order_uid не раздувал кардинальность
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
 
-// 		status := strconv.Itoa(rw.Status())
-// 		prom_metrics.HTTPRequests.WithLabelValues(method, status).Inc()
-// 	})
-// }
+		status := rw.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		prom_metrics.HTTPDuration.WithLabelValues(method, route).Observe(time.Since(t1).Seconds())
+		prom_metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	})
+}
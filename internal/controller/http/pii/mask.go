@@ -0,0 +1,96 @@
+package pii
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const stars = "***"
+
+// phoneTail — сколько последних цифр телефона остаётся видимыми.
+const phoneTail = 4
+
+// apply маскирует значение по режиму; partial — функцией поля.
+// Пустое значение остаётся пустым.
+func apply(mode Mode, s string, partial func(string) string) string {
+	if s == "" {
+		return s
+	}
+	switch mode {
+	case ModeNone:
+		return s
+	case ModePartial:
+		return partial(s)
+	}
+	return stars
+}
+
+// phone оставляет код страны и последние цифры: +79991234567 -> +7***4567,
+// +9720000000 -> +972***0000. Код страны определяется только в международном
+// формате с "+", у номера без него остаются лишь последние цифры.
+func phone(s string) string {
+	digits := make([]rune, 0, len(s))
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+
+	prefix, code := "", 0
+	if strings.HasPrefix(strings.TrimSpace(s), "+") {
+		code = callingCodeLen(digits)
+		prefix = "+" + string(digits[:code])
+	}
+	if len(digits) <= code+phoneTail {
+		return stars
+	}
+	return prefix + stars + string(digits[len(digits)-phoneTail:])
+}
+
+// twoDigitCodes — двузначные коды стран E.164; из однозначных есть только 1 и 7,
+// остальные коды трёхзначные.
+var twoDigitCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true, "34": true,
+	"36": true, "39": true, "40": true, "41": true, "43": true, "44": true, "45": true,
+	"46": true, "47": true, "48": true, "49": true, "51": true, "52": true, "53": true,
+	"54": true, "55": true, "56": true, "57": true, "58": true, "60": true, "61": true,
+	"62": true, "63": true, "64": true, "65": true, "66": true, "81": true, "82": true,
+	"84": true, "86": true, "90": true, "91": true, "92": true, "93": true, "94": true,
+	"95": true, "98": true,
+}
+
+// callingCodeLen — длина кода страны в начале digits.
+func callingCodeLen(digits []rune) int {
+	n := 3
+	switch {
+	case len(digits) > 0 && (digits[0] == '1' || digits[0] == '7'):
+		n = 1
+	case len(digits) >= 2 && twoDigitCodes[string(digits[:2])]:
+		n = 2
+	}
+	return min(n, len(digits))
+}
+
+// email оставляет первую букву имени ящика и домен: john@mail.ru -> j***@mail.ru.
+func email(s string) string {
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok || local == "" {
+		return words(s)
+	}
+	return first(local) + stars + "@" + domain
+}
+
+// words оставляет первую букву каждого слова: Ivan Ivanov -> I*** I***.
+func words(s string) string {
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		fields[i] = first(f) + stars
+	}
+	return strings.Join(fields, " ")
+}
+
+func first(s string) string {
+	r, _ := utf8.DecodeRuneInString(s)
+	return string(r)
+}
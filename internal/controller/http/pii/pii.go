@@ -0,0 +1,154 @@
+package pii
+
+import (
+	"context"
+	"fmt"
+
+	custommiddleware "github.com/RozmiDan/wb_tech_testtask/internal/controller/http/middleware"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+)
+
+// Mode — как поле показывается клиенту без привилегированной роли.
+type Mode string
+
+const (
+	// ModeNone — поле отдаётся как есть
+	ModeNone Mode = "none"
+	// ModePartial — остаётся часть значения: +972***0000, j***@mail.ru, I*** I***
+	ModePartial Mode = "partial"
+	// ModeFull — значение целиком заменяется на ***
+	ModeFull Mode = "full"
+)
+
+// Поля заказа, которые можно маскировать; имена совпадают с колонками выгрузки.
+const (
+	FieldName    = "delivery.name"
+	FieldPhone   = "delivery.phone"
+	FieldEmail   = "delivery.email"
+	FieldAddress = "delivery.address"
+	FieldCity    = "delivery.city"
+	FieldRegion  = "delivery.region"
+)
+
+// DefaultPolicy — политика, если PII_MASK_POLICY не переопределяет поле.
+func DefaultPolicy() map[string]Mode {
+	return map[string]Mode{
+		FieldName:    ModePartial,
+		FieldPhone:   ModePartial,
+		FieldEmail:   ModePartial,
+		FieldAddress: ModeFull,
+		FieldCity:    ModeNone,
+		FieldRegion:  ModeNone,
+	}
+}
+
+// Masker скрывает персональные данные получателя в заказах, которые
+// отдаются клиенту без роли UnmaskedRole. Заказы из кэша не изменяются:
+// маскируется копия.
+type Masker struct {
+	disabled bool
+	policy   map[string]Mode
+	unmasked custommiddleware.Role
+}
+
+// Option -.
+type Option func(*Masker) error
+
+// Policy переопределяет режим отдельных полей: поле -> none|partial|full.
+func Policy(fields map[string]string) Option {
+	return func(m *Masker) error {
+		for field, name := range fields {
+			if _, ok := m.policy[field]; !ok {
+				return fmt.Errorf("unknown pii field %q", field)
+			}
+			mode := Mode(name)
+			switch mode {
+			case ModeNone, ModePartial, ModeFull:
+			default:
+				return fmt.Errorf("unknown mask mode %q for %s", name, field)
+			}
+			m.policy[field] = mode
+		}
+		return nil
+	}
+}
+
+// UnmaskedRole задаёт роль, начиная с которой данные не маскируются.
+func UnmaskedRole(name string) Option {
+	return func(m *Masker) error {
+		role, err := custommiddleware.ParseRole(name)
+		if err != nil {
+			return fmt.Errorf("unmasked role: %w", err)
+		}
+		m.unmasked = role
+		return nil
+	}
+}
+
+// Disabled отключает маскирование: заказы отдаются как есть.
+func Disabled() Option {
+	return func(m *Masker) error {
+		m.disabled = true
+		return nil
+	}
+}
+
+func New(opts ...Option) (*Masker, error) {
+	m := &Masker{
+		policy:   DefaultPolicy(),
+		unmasked: custommiddleware.RoleAdmin,
+	}
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Unmasked сообщает, видит ли клиент запроса данные без маскирования.
+// Запрос без аутентифицированного клиента (в том числе при AUTH_ENABLED=false)
+// получает маскированные данные.
+func (m *Masker) Unmasked(ctx context.Context) bool {
+	if m.disabled {
+		return true
+	}
+	p, ok := custommiddleware.PrincipalFrom(ctx)
+	return ok && p.Role >= m.unmasked
+}
+
+// Order возвращает заказ в том виде, в каком его можно отдать клиенту запроса.
+func (m *Masker) Order(ctx context.Context, o *entity.OrderResponse) *entity.OrderResponse {
+	if o == nil || m.Unmasked(ctx) {
+		return o
+	}
+	return m.mask(o)
+}
+
+// List — то же, что Order, для страницы заказов.
+func (m *Masker) List(ctx context.Context, l *entity.OrderListResponse) *entity.OrderListResponse {
+	if l == nil || m.Unmasked(ctx) {
+		return l
+	}
+
+	out := &entity.OrderListResponse{
+		Orders:     make([]*entity.OrderResponse, len(l.Orders)),
+		NextCursor: l.NextCursor,
+	}
+	for i, o := range l.Orders {
+		out.Orders[i] = m.mask(o)
+	}
+	return out
+}
+
+func (m *Masker) mask(o *entity.OrderResponse) *entity.OrderResponse {
+	c := *o
+	d := &c.Delivery
+	d.Name = apply(m.policy[FieldName], d.Name, words)
+	d.Phone = apply(m.policy[FieldPhone], d.Phone, phone)
+	d.Email = apply(m.policy[FieldEmail], d.Email, email)
+	d.Address = apply(m.policy[FieldAddress], d.Address, words)
+	d.City = apply(m.policy[FieldCity], d.City, words)
+	d.Region = apply(m.policy[FieldRegion], d.Region, words)
+	return &c
+}
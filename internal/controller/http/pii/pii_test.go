@@ -0,0 +1,57 @@
+package pii
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartialMasks(t *testing.T) {
+	cases := []struct {
+		mask     func(string) string
+		in, want string
+	}{
+		{phone, "+79991234567", "+7***4567"},
+		{phone, "+9720000000", "+972***0000"},
+		{phone, "+44 20 7946 0958", "+44***0958"},
+		{phone, "+12025550123", "+1***0123"},
+		{phone, "8 (999) 123-45-67", "***4567"},
+		{phone, "+1234", "***"},
+		{phone, "+", "***"},
+		{email, "john@mail.ru", "j***@mail.ru"},
+		{email, "not-an-email", "n***"},
+		{words, "Иван  Иванов", "И*** И***"},
+		{words, "Ploshad Mira 15", "P*** M*** 1***"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, c.mask(c.in), c.in)
+	}
+
+	require.Equal(t, "", apply(ModeFull, "", words), "empty value stays empty")
+	require.Equal(t, "***", apply(ModeFull, "Moscow", words))
+	require.Equal(t, "Moscow", apply(ModeNone, "Moscow", words))
+}
+
+func TestNewRejectsBadPolicy(t *testing.T) {
+	_, err := New(Policy(map[string]string{"payment.bank": "full"}))
+	require.Error(t, err)
+
+	_, err = New(Policy(map[string]string{FieldPhone: "hash"}))
+	require.Error(t, err)
+
+	_, err = New(UnmaskedRole("root"))
+	require.Error(t, err)
+}
+
+func TestDisabledAndNil(t *testing.T) {
+	m, err := New(Disabled())
+	require.NoError(t, err)
+	require.True(t, m.Unmasked(context.Background()))
+
+	m, err = New()
+	require.NoError(t, err)
+	require.False(t, m.Unmasked(context.Background()), "no principal means masked")
+	require.Nil(t, m.Order(context.Background(), nil))
+	require.Nil(t, m.List(context.Background(), nil))
+}
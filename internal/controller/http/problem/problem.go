@@ -0,0 +1,80 @@
+// Package problem формирует ответы об ошибках в формате RFC 7807 (application/problem+json).
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+)
+
+const ContentType = "application/problem+json"
+
+// TypeValidation — тип проблемы для заказов, не прошедших валидацию.
+const TypeValidation = "/problems/validation-error"
+
+// Details — тело ответа об ошибке; Errors — расширение для ошибок валидации.
+type Details struct {
+	Type      string             `json:"type" example:"/problems/validation-error"`
+	Title     string             `json:"title" example:"Order validation failed"`
+	Status    int                `json:"status" example:"422"`
+	Detail    string             `json:"detail,omitempty" example:"order has 2 violations"`
+	Instance  string             `json:"instance,omitempty" example:"/order/b563feb7b2b84b6test"`
+	RequestID string             `json:"request_id,omitempty"`
+	Errors    []entity.Violation `json:"errors,omitempty"`
+}
+
+// Validation описывает нарушения валидации заказа, статус 422.
+func Validation(r *http.Request, verr *entity.ValidationError) Details {
+	reqID, _ := r.Context().Value(entity.RequestIDKey{}).(string)
+
+	detail := fmt.Sprintf("order has %d violations", len(verr.Violations))
+	if len(verr.Violations) == 1 {
+		detail = "order has 1 violation"
+	}
+	return Details{
+		Type:      TypeValidation,
+		Title:     "Order validation failed",
+		Status:    http.StatusUnprocessableEntity,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: reqID,
+		Errors:    verr.Violations,
+	}
+}
+
+// DecodeViolation превращает ошибки типов и неизвестные поля JSON в нарушения
+// валидации с путём поля; остальные ошибки разбора остаются ответом 400.
+func DecodeViolation(err error) *entity.ValidationError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &entity.ValidationError{Violations: entity.Violations{{
+			Field:   typeErr.Field,
+			Code:    entity.CodeInvalidType,
+			Message: "must be " + typeErr.Type.String() + ", got " + typeErr.Value,
+		}}}
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &entity.ValidationError{Violations: entity.Violations{{
+			Field:   strings.Trim(field, `"`),
+			Code:    entity.CodeUnknownField,
+			Message: "unknown field",
+		}}}
+	}
+	return nil
+}
+
+// Write отправляет проблему клиенту.
+func Write(w http.ResponseWriter, p Details) error {
+	body, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	_, err = w.Write(body)
+	return err
+}
@@ -0,0 +1,39 @@
+package response
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+)
+
+//go:embed templates/page.html
+var templatesFS embed.FS
+
+var pageTmpl = template.Must(template.ParseFS(templatesFS, "templates/page.html"))
+
+// page — данные HTML-представления: либо ошибка, либо данные ответа.
+type page struct {
+	Title  string
+	Status int
+	Error  *ErrorBody
+	Data   any
+}
+
+// JSON — данные ответа в читаемом виде для блока <pre>.
+func (p page) JSON() (string, error) {
+	b, err := json.MarshalIndent(p.Data, "", "  ")
+	return string(b), err
+}
+
+func writeHTML(w http.ResponseWriter, status int, p page) error {
+	var buf bytes.Buffer
+	if err := pageTmpl.Execute(&buf, p); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentTypeHTML)
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
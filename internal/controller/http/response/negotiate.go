@@ -0,0 +1,65 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WantsHTML сообщает, что по заголовку Accept клиент предпочитает text/html
+// представлению application/json. Без Accept и при равном весе отдаётся JSON.
+func WantsHTML(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+	return quality(accept, "text", "html") > quality(accept, "application", "json")
+}
+
+// quality возвращает вес медиатипа type/subtype по самому точному
+// подходящему диапазону Accept (RFC 9110, 12.5.1).
+func quality(accept, typ, subtype string) float64 {
+	best, bestSpecificity := 0.0, -1
+
+	for _, rng := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(strings.TrimSpace(rng), ";")
+		t, s, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaRange)), "/")
+		if !ok {
+			continue
+		}
+
+		var specificity int
+		switch {
+		case t == typ && s == subtype:
+			specificity = 2
+		case t == typ && s == "*":
+			specificity = 1
+		case t == "*" && s == "*":
+			specificity = 0
+		default:
+			continue
+		}
+		if specificity <= bestSpecificity {
+			continue
+		}
+
+		bestSpecificity, best = specificity, parseQ(params)
+	}
+
+	return best
+}
+
+func parseQ(params string) float64 {
+	for _, p := range strings.Split(params, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if !ok || !strings.EqualFold(k, "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(v, 64)
+		if err != nil || q < 0 || q > 1 {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
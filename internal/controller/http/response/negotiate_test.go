@@ -0,0 +1,32 @@
+package response
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWantsHTML(t *testing.T) {
+	cases := map[string]bool{
+		"":                                  false,
+		"*/*":                               false,
+		"application/json":                  false,
+		"text/html":                         true,
+		"text/*":                            true,
+		"text/html;q=0.5, application/json": false,
+		"application/json;q=0.5, text/html": true,
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": true,
+		"application/*, text/html;q=0.9":                                  false,
+		"text/html;q=0, */*":                                              false,
+		"TEXT/HTML":                                                       true,
+	}
+
+	for accept, want := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		require.Equal(t, want, WantsHTML(r), "Accept: %q", accept)
+	}
+}
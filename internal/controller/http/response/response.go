@@ -0,0 +1,86 @@
+// Package response — общий формат ответов HTTP API: JSON-тело с правильным
+// Content-Type, единый конверт ошибок {code, message, request_id} и выбор
+// представления (JSON или HTML) по заголовку Accept.
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+const (
+	ContentTypeJSON = "application/json; charset=utf-8"
+	ContentTypeHTML = "text/html; charset=utf-8"
+)
+
+// Машиночитаемые коды ошибок поля code.
+const (
+	CodeInvalidArgument  = "invalid_argument"
+	CodeUnauthenticated  = "unauthenticated"
+	CodeForbidden        = "forbidden"
+	CodeMalformedBody    = "malformed_body"
+	CodeNotFound         = "not_found"
+	CodeAlreadyExists    = "already_exists"
+	CodeConflict         = "conflict"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeTimeout          = "timeout"
+	CodeInternal         = "internal"
+)
+
+// ErrorBody — тело ответа об ошибке.
+type ErrorBody struct {
+	Code      string `json:"code" example:"not_found"`
+	Message   string `json:"message" example:"order not found"`
+	RequestID string `json:"request_id,omitempty" example:"host/abcdef-000001"`
+}
+
+// JSON отправляет v со статусом status. Если клиент предпочитает text/html,
+// то же содержимое отдаётся HTML-страницей.
+func JSON(w http.ResponseWriter, r *http.Request, logger *zap.Logger, status int, v any) {
+	send(logger, writeJSON(w, r, status, v))
+}
+
+// Created отвечает 201 с заголовком Location на созданный ресурс.
+func Created(w http.ResponseWriter, r *http.Request, logger *zap.Logger, location string, v any) {
+	w.Header().Set("Location", location)
+	send(logger, writeJSON(w, r, http.StatusCreated, v))
+}
+
+// Error отправляет ошибку в едином конверте; request_id берётся из контекста запроса.
+func Error(w http.ResponseWriter, r *http.Request, logger *zap.Logger, status int, code, message string) {
+	reqID, _ := r.Context().Value(entity.RequestIDKey{}).(string)
+	body := ErrorBody{Code: code, Message: message, RequestID: reqID}
+
+	if WantsHTML(r) {
+		w.Header().Set("Vary", "Accept")
+		send(logger, writeHTML(w, status, page{Title: http.StatusText(status), Status: status, Error: &body}))
+
+		return
+	}
+	send(logger, writeJSON(w, r, status, body))
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	w.Header().Set("Vary", "Accept")
+	if WantsHTML(r) {
+		return writeHTML(w, status, page{Title: http.StatusText(status), Status: status, Data: v})
+	}
+
+	b, err := json.MarshalIndent(v, "", "	")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
+
+func send(logger *zap.Logger, err error) {
+	if err != nil {
+		logger.Error("error sending the response", zap.Error(err))
+	}
+}
@@ -2,18 +2,29 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	_ "github.com/RozmiDan/wb_tech_testtask/docs"
 	"github.com/RozmiDan/wb_tech_testtask/internal/config"
 	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/addhandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/bulkhandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/cachehandler"
 	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/drophandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/exporthandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/healthhandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/lookuphandler"
 	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/mainhandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/searchhandler"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/handlers/statushandler"
 	custommiddleware "github.com/RozmiDan/wb_tech_testtask/internal/controller/http/middleware"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/pii"
+	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/response"
 	"github.com/RozmiDan/wb_tech_testtask/internal/controller/http/webui"
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 )
@@ -21,30 +32,87 @@ import (
 type UseCase interface {
 	GetOrderInfo(ctx context.Context, orderUID string) (*entity.OrderResponse, error)
 	AddOrderInfo(ctx context.Context, order *entity.OrderInfo) error
+	AddOrders(ctx context.Context, orders []*entity.OrderInfo) []entity.OrderResult
+	SearchOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderListResponse, error)
+	ExportOrders(ctx context.Context, filter entity.OrderFilter, fn func(*entity.OrderResponse) error) error
+	GetOrdersByTrack(ctx context.Context, trackNumber string) (*entity.OrderListResponse, error)
+	GetOrderByTransaction(ctx context.Context, transaction string) (*entity.OrderResponse, error)
+	UpdateOrderStatus(ctx context.Context, upd *entity.StatusUpdate, source string) (*entity.StatusChange, error)
+	GetCustomerOrders(ctx context.Context, customerID string, limit int, after *entity.OrderCursor) (*entity.OrderListResponse, error)
+	EvictOrder(ctx context.Context, orderUID string) (bool, error)
+	PurgeCache(ctx context.Context)
 }
 
-func InitServer(cfg *config.Config, logger *zap.Logger, uc UseCase) *http.Server {
+func InitServer(cfg *config.Config, logger *zap.Logger, uc UseCase, ready healthhandler.ReadinessChecker) (*http.Server, error) {
 	baseLog := logger.With(zap.String("layer", "Controller"))
 
+	auth, err := newAuthenticator(cfg, baseLog)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	masker, err := newMasker(cfg, baseLog)
+	if err != nil {
+		return nil, fmt.Errorf("pii: %w", err)
+	}
+
+	reader := auth.Require(custommiddleware.RoleReader)
+	writer := auth.Require(custommiddleware.RoleWriter)
+	admin := auth.Require(custommiddleware.RoleAdmin)
+
 	router := chi.NewRouter()
 
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.RequestID)
 	router.Use(middleware.URLFormat)
-	// router.Use(custommiddleware.PrometheusMiddleware)
-	router.Use(custommiddleware.CustomLogger(baseLog, cfg.HTTPTimeout))
+	router.Use(custommiddleware.PrometheusMiddleware)
+	router.Use(custommiddleware.CustomLogger(baseLog))
+
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, r, baseLog, http.StatusNotFound, response.CodeNotFound, "route not found")
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, r, baseLog, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "method not allowed")
+	})
+
+	// обычные ручки ограничены HTTP_TIMEOUT, потоковая загрузка — своим лимитом
+	api := router.With(custommiddleware.Timeout(cfg.HTTPTimeout))
 
-	router.Get("/swagger/*", httpSwagger.WrapHandler)
-	// router.Handle("/metrics", promhttp.Handler())
+	// без аутентификации: документация, метрики, пробы и статический UI
+	api.Get("/swagger/*", httpSwagger.WrapHandler)
+	api.Handle("/metrics", promhttp.Handler())
+	api.Get("/healthz", healthhandler.Liveness())
+	api.Get("/readyz", healthhandler.Readiness(baseLog, ready))
 
 	// static UI
-	router.Get("/", webui.Index())
-	router.Handle("/static/*", webui.Static())
+	api.Get("/", webui.Index())
+	api.Handle("/static/*", webui.Static())
 
+	// reader: чтение заказов
 	// GET http://localhost:8081/order/<order_uid>
-	router.Get("/order/{order_uid}", mainhandler.New(baseLog, uc))
-	router.Get("/service/drop", drophandler.New(baseLog))
-	router.Post("/order/{order_uid}", addhandler.New(baseLog, uc))
+	api.With(reader).Get("/order/{order_uid}", mainhandler.New(baseLog, uc, masker))
+
+	// GET http://localhost:8081/orders?customer_id=<id>&limit=20&cursor=<next_cursor>
+	api.With(reader).Get("/orders", searchhandler.New(baseLog, uc, masker))
+	api.With(reader).Get("/orders/by-track/{track_number}", lookuphandler.NewByTrack(baseLog, uc, masker))
+	api.With(reader).Get("/orders/by-transaction/{transaction}", lookuphandler.NewByTransaction(baseLog, uc, masker))
+	api.With(reader).Get("/customers/{customer_id}/orders", lookuphandler.NewByCustomer(baseLog, uc, masker))
+
+	// GET http://localhost:8081/orders/export?format=csv&columns=order_uid,item.name&date_from=...
+	router.With(reader, custommiddleware.Timeout(cfg.HTTPExportTimeout)).
+		Get("/orders/export", exporthandler.New(baseLog, uc, masker, cfg.HTTPTimeout))
+
+	// writer: запись заказов и смена статуса
+	api.With(writer).Post("/order/{order_uid}", addhandler.New(baseLog, uc))
+	api.With(writer).Patch("/order/{order_uid}/status", statushandler.New(baseLog, uc))
+
+	// POST http://localhost:8081/orders:bulk, тело — NDJSON, по заказу на строку
+	router.With(writer, custommiddleware.Timeout(cfg.HTTPBulkTimeout)).
+		Post("/orders:bulk", bulkhandler.New(baseLog, uc, cfg.HTTPTimeout))
+
+	// admin: управление кэшем и аварийная остановка
+	api.With(admin).Delete("/admin/cache", cachehandler.NewPurge(baseLog, uc))
+	api.With(admin).Delete("/admin/cache/{order_uid}", cachehandler.NewEvict(baseLog, uc))
+	api.With(admin).Get("/service/drop", drophandler.New(baseLog))
 
 	server := &http.Server{
 		Addr:         cfg.HTTPPort,
@@ -54,5 +122,42 @@ func InitServer(cfg *config.Config, logger *zap.Logger, uc UseCase) *http.Server
 		IdleTimeout:  cfg.HTTPIdleTimeout,
 	}
 
-	return server
+	return server, nil
+}
+
+// newAuthenticator собирает проверку API-ключей и JWT из конфигурации.
+func newAuthenticator(cfg *config.Config, logger *zap.Logger) (*custommiddleware.Authenticator, error) {
+	if !cfg.AuthEnabled {
+		return custommiddleware.NewAuthenticator(logger, custommiddleware.AuthDisabled())
+	}
+
+	opts := []custommiddleware.AuthOption{
+		custommiddleware.APIKeys(cfg.AuthAPIKeys),
+		custommiddleware.AnonymousRole(cfg.AuthAnonymousRole),
+	}
+	if cfg.AuthJWTSecret != "" {
+		opts = append(opts,
+			custommiddleware.JWTSecret(cfg.AuthJWTSecret),
+			custommiddleware.JWTIssuer(cfg.AuthJWTIssuer),
+			custommiddleware.JWTAudience(cfg.AuthJWTAudience),
+		)
+	}
+	return custommiddleware.NewAuthenticator(logger, opts...)
+}
+
+// newMasker собирает политику маскирования персональных данных из конфигурации.
+func newMasker(cfg *config.Config, logger *zap.Logger) (*pii.Masker, error) {
+	if !cfg.PIIMaskingEnabled {
+		return pii.New(pii.Disabled())
+	}
+	// без аутентификации роль клиента неизвестна, и маскируется каждый ответ
+	if !cfg.AuthEnabled {
+		logger.Warn("authentication is disabled, personal data is masked for every client",
+			zap.String("unmasked_role", cfg.PIIUnmaskedRole))
+	}
+
+	return pii.New(
+		pii.UnmaskedRole(cfg.PIIUnmaskedRole),
+		pii.Policy(cfg.PIIMaskPolicy),
+	)
 }
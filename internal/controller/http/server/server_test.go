@@ -0,0 +1,607 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/config"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/RozmiDan/wb_tech_testtask/internal/health"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeUseCase struct{}
+
+func (fakeUseCase) GetOrderInfo(_ context.Context, orderUID string) (*entity.OrderResponse, error) {
+	if orderUID == "known" {
+		return &entity.OrderResponse{OrderUID: orderUID, Delivery: entity.DeliveryPublic{
+			Name: "Test Testov", Phone: "+9720000000", Email: "test@gmail.com",
+			Address: "Ploshad Mira 15", City: "Kiryat Mozkin",
+		}}, nil
+	}
+	return nil, entity.ErrorOrderNotFound
+}
+
+func (fakeUseCase) AddOrderInfo(_ context.Context, order *entity.OrderInfo) error {
+	if order.OrderUID == "dup" {
+		return entity.ErrAlreadyExists
+	}
+	if err := order.ValidateOrder(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f fakeUseCase) AddOrders(ctx context.Context, orders []*entity.OrderInfo) []entity.OrderResult {
+	results := make([]entity.OrderResult, len(orders))
+	for i, o := range orders {
+		results[i] = entity.OrderResult{OrderUID: o.OrderUID, Outcome: entity.OutcomeInserted}
+		switch err := f.AddOrderInfo(ctx, o); {
+		case errors.Is(err, entity.ErrAlreadyExists):
+			results[i].Outcome, results[i].Err = entity.OutcomeAlreadyExists, err
+		case err != nil:
+			results[i].Outcome, results[i].Err = entity.OutcomeInvalid, err
+		}
+	}
+	return results
+}
+
+func (fakeUseCase) SearchOrders(context.Context, entity.OrderFilter) (*entity.OrderListResponse, error) {
+	return &entity.OrderListResponse{Orders: []*entity.OrderResponse{}}, nil
+}
+
+func (fakeUseCase) ExportOrders(_ context.Context, f entity.OrderFilter, fn func(*entity.OrderResponse) error) error {
+	if f.CustomerID == "broken" {
+		return entity.ErrInternal
+	}
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	orders := []*entity.OrderResponse{
+		{OrderUID: "a", DateCreated: created, Items: []entity.ItemPublic{
+			{ChrtID: 1, Name: "Mascaras", Price: 453},
+			{ChrtID: 2, Name: "Lipstick, red", Price: 100},
+		}},
+		{OrderUID: "b", DateCreated: created, Delivery: entity.DeliveryPublic{Phone: "+9720000000"}},
+	}
+	for _, o := range orders {
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fakeUseCase) GetOrdersByTrack(context.Context, string) (*entity.OrderListResponse, error) {
+	return nil, entity.ErrorOrderNotFound
+}
+
+func (fakeUseCase) GetOrderByTransaction(context.Context, string) (*entity.OrderResponse, error) {
+	return nil, entity.ErrorOrderNotFound
+}
+
+func (fakeUseCase) GetCustomerOrders(context.Context, string, int, *entity.OrderCursor) (*entity.OrderListResponse, error) {
+	return &entity.OrderListResponse{Orders: []*entity.OrderResponse{}}, nil
+}
+
+func (fakeUseCase) UpdateOrderStatus(_ context.Context, upd *entity.StatusUpdate, source string) (*entity.StatusChange, error) {
+	if upd.Status != entity.StatusPaid {
+		return nil, entity.ErrInvalidTransition
+	}
+	return &entity.StatusChange{OrderUID: upd.OrderUID, From: entity.StatusCreated, To: upd.Status, Source: source}, nil
+}
+
+func (fakeUseCase) EvictOrder(_ context.Context, orderUID string) (bool, error) {
+	return orderUID == "known", nil
+}
+
+func (fakeUseCase) PurgeCache(context.Context) {}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cfg := &config.Config{HTTPTimeout: 2 * time.Second, HTTPIdleTimeout: time.Second, HTTPBulkTimeout: 5 * time.Second, HTTPExportTimeout: 5 * time.Second}
+	srv, err := InitServer(cfg, zap.NewNop(), fakeUseCase{}, health.New(time.Second))
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(srv.Handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestMetricsEndpointExposesHTTPMetrics(t *testing.T) {
+	ts := newTestServer(t)
+
+	status, _ := get(t, ts.URL+"/order/known")
+	require.Equal(t, http.StatusOK, status)
+	status, _ = get(t, ts.URL+"/order/missing")
+	require.Equal(t, http.StatusNotFound, status)
+
+	status, body := get(t, ts.URL+"/metrics")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `http_requests_total{method="GET",route="/order/{order_uid}",status="200"}`)
+	require.Contains(t, body, `http_requests_total{method="GET",route="/order/{order_uid}",status="404"}`)
+	require.Contains(t, body, `http_request_duration_seconds_bucket{method="GET",route="/order/{order_uid}"`)
+	require.Contains(t, body, `http_requests_in_flight{method="GET"}`)
+}
+
+func TestReadinessReportsComponentsAndDrains(t *testing.T) {
+	checker := health.New(time.Second)
+	checker.Register("postgres", func(context.Context) error { return nil })
+
+	cfg := &config.Config{HTTPTimeout: 2 * time.Second}
+	srv, err := InitServer(cfg, zap.NewNop(), fakeUseCase{}, checker)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler)
+	t.Cleanup(ts.Close)
+
+	status, body := get(t, ts.URL+"/healthz")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"status":"up"}`, body)
+
+	status, body = get(t, ts.URL+"/readyz")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `"postgres":{"status":"up"`)
+
+	checker.SetShuttingDown()
+	status, body = get(t, ts.URL+"/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Contains(t, body, `"shutdown":{"status":"down"`)
+
+	status, _ = get(t, ts.URL+"/healthz")
+	require.Equal(t, http.StatusOK, status, "liveness is not affected by draining")
+}
+
+func TestUpdateOrderStatusEndpoint(t *testing.T) {
+	ts := newTestServer(t)
+
+	patch := func(uid, body string) int {
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/order/"+uid+"/status", strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, patch("known", `{"status":"paid"}`))
+	require.Equal(t, http.StatusConflict, patch("known", `{"status":"delivered"}`))
+	require.Equal(t, http.StatusBadRequest, patch("known", `{"status":"lost"}`))
+	require.Equal(t, http.StatusBadRequest, patch("known", `{"state":"paid"}`))
+}
+
+func TestAddOrderReturnsValidationProblem(t *testing.T) {
+	ts := newTestServer(t)
+
+	post := func(uid, body string) (*http.Response, []byte) {
+		resp, err := http.Post(ts.URL+"/order/"+uid, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, raw
+	}
+
+	resp, raw := post("bad", `{"order_uid":"bad","items":[]}`)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	var p struct {
+		Type     string             `json:"type"`
+		Status   int                `json:"status"`
+		Instance string             `json:"instance"`
+		Errors   []entity.Violation `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &p))
+	require.Equal(t, "/problems/validation-error", p.Type)
+	require.Equal(t, http.StatusUnprocessableEntity, p.Status)
+	require.Equal(t, "/order/bad", p.Instance)
+	require.NotEmpty(t, p.Errors)
+	require.Contains(t, p.Errors, entity.Violation{Field: "track_number", Code: entity.CodeRequired, Message: "must not be empty"})
+
+	resp, raw = post("bad", `{"order_uid":"bad","delivery":{"zip":123}}`)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	require.Contains(t, string(raw), `"field": "delivery.zip"`)
+	require.Contains(t, string(raw), `"code": "invalid_type"`)
+
+	resp, raw = post("bad", `{"order_uid":"bad","color":"red"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	require.Contains(t, string(raw), `"code": "unknown_field"`)
+
+	resp, _ = post("bad", `{"order_uid":`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestErrorsUseJSONEnvelope(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/order/missing")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var body struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, "not_found", body.Code)
+	require.Equal(t, "order not found", body.Message)
+	require.NotEmpty(t, body.RequestID)
+
+	status, raw := get(t, ts.URL+"/no/such/route")
+	require.Equal(t, http.StatusNotFound, status)
+	require.Contains(t, raw, `"code": "not_found"`)
+}
+
+func TestAddOrderCreatedAndDuplicate(t *testing.T) {
+	ts := newTestServer(t)
+
+	sample, err := os.ReadFile("../../../../producer_samples/01.json")
+	require.NoError(t, err)
+
+	resp, err := http.Post(ts.URL+"/order/b562feb7b2b84b6test1", "application/json", strings.NewReader(string(sample)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "/order/b562feb7b2b84b6test1", resp.Header.Get("Location"))
+
+	resp, err = http.Post(ts.URL+"/order/dup", "application/json", strings.NewReader(`{"order_uid":"dup"}`))
+	require.NoError(t, err)
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+	require.Contains(t, string(raw), `"code": "already_exists"`)
+}
+
+func TestOrderNegotiatesHTML(t *testing.T) {
+	ts := newTestServer(t)
+
+	fetch := func(uid, accept string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/order/"+uid, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(raw)
+	}
+
+	resp, body := fetch("known", "text/html,application/xhtml+xml,*/*;q=0.8")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Contains(t, body, "known")
+
+	resp, body = fetch("missing", "text/html")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Contains(t, body, "order not found")
+
+	resp, _ = fetch("known", "*/*")
+	require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+}
+
+func compactSample(t *testing.T) string {
+	t.Helper()
+
+	sample, err := os.ReadFile("../../../../producer_samples/01.json")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, json.Compact(&buf, sample))
+	return buf.String()
+}
+
+func TestBulkOrdersStreamsPerLineResults(t *testing.T) {
+	ts := newTestServer(t)
+
+	body := strings.Join([]string{
+		compactSample(t),
+		`{"order_uid":"dup"}`,
+		``,
+		`{"order_uid":"bad"}`,
+		`{"order_uid":`,
+		`{"order_uid":"bad","color":"red"}`,
+	}, "\n")
+
+	resp, err := http.Post(ts.URL+"/orders:bulk", "application/x-ndjson", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	type result struct {
+		Line     int                `json:"line"`
+		OrderUID string             `json:"order_uid"`
+		Result   string             `json:"result"`
+		Reason   string             `json:"reason"`
+		Errors   []entity.Violation `json:"errors"`
+	}
+	var results []result
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var r result
+		require.NoError(t, dec.Decode(&r))
+		results = append(results, r)
+	}
+
+	require.Len(t, results, 5)
+	require.Equal(t, result{Line: 1, OrderUID: "b562feb7b2b84b6test1", Result: "inserted"}, results[0])
+	require.Equal(t, result{Line: 2, OrderUID: "dup", Result: "duplicate"}, results[1])
+
+	require.Equal(t, 4, results[2].Line, "blank lines are skipped but counted")
+	require.Equal(t, "invalid", results[2].Result)
+	require.NotEmpty(t, results[2].Errors)
+
+	require.Equal(t, "invalid", results[3].Result)
+	require.Contains(t, results[3].Reason, "invalid JSON")
+
+	require.Equal(t, "invalid", results[4].Result)
+	require.Equal(t, entity.CodeUnknownField, results[4].Errors[0].Code)
+}
+
+// batchingUseCase запоминает размеры пачек AddOrders; первая пачка
+// задерживается, чтобы следующие строки успели прийти.
+type batchingUseCase struct {
+	fakeUseCase
+	mu    sync.Mutex
+	sizes []int
+}
+
+func (b *batchingUseCase) AddOrders(ctx context.Context, orders []*entity.OrderInfo) []entity.OrderResult {
+	b.mu.Lock()
+	first := len(b.sizes) == 0
+	b.sizes = append(b.sizes, len(orders))
+	b.mu.Unlock()
+	if first {
+		time.Sleep(50 * time.Millisecond)
+	}
+	return b.fakeUseCase.AddOrders(ctx, orders)
+}
+
+func TestBulkOrdersSavesReceivedLinesInBatches(t *testing.T) {
+	uc := &batchingUseCase{}
+	cfg := &config.Config{HTTPTimeout: 2 * time.Second, HTTPIdleTimeout: time.Second, HTTPBulkTimeout: 5 * time.Second, HTTPExportTimeout: 5 * time.Second}
+	srv, err := InitServer(cfg, zap.NewNop(), uc, health.New(time.Second))
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler)
+	t.Cleanup(ts.Close)
+
+	const lines = 250
+	body := strings.Repeat(`{"order_uid":"dup"}`+"\n", lines-1) + `{"order_uid":`
+	resp, err := http.Post(ts.URL+"/orders:bulk", "application/x-ndjson", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	n := 0
+	for scanner.Scan() {
+		n++
+		want := `{"line":` + strconv.Itoa(n) + `,"order_uid":"dup","result":"duplicate"}`
+		if n == lines {
+			require.Contains(t, scanner.Text(), `"result":"invalid"`, "bad JSON in a batch does not fail the others")
+			continue
+		}
+		require.JSONEq(t, want, scanner.Text(), "results keep the line order")
+	}
+	require.Equal(t, lines, n)
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	require.Less(t, len(uc.sizes), lines-1, "lines are saved in batches, not one by one")
+	for _, size := range uc.sizes {
+		require.LessOrEqual(t, size, 100)
+	}
+}
+
+func TestBulkOrdersRespondsBeforeBodyEnds(t *testing.T) {
+	ts := newTestServer(t)
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/orders:bulk", pr)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	done := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			close(done)
+			return
+		}
+		done <- resp
+	}()
+
+	_, err = io.WriteString(pw, `{"order_uid":"dup"}`+"\n")
+	require.NoError(t, err)
+
+	var resp *http.Response
+	select {
+	case resp = <-done:
+		require.NotNil(t, resp)
+	case <-time.After(time.Second):
+		t.Fatal("no response headers while the body is still open")
+	}
+	defer resp.Body.Close()
+
+	// первая строка приходит, пока клиент ещё не закрыл тело запроса
+	lines := bufio.NewScanner(resp.Body)
+	require.True(t, lines.Scan())
+	require.JSONEq(t, `{"line":1,"order_uid":"dup","result":"duplicate"}`, lines.Text())
+
+	_, err = io.WriteString(pw, compactSample(t)+"\n")
+	require.NoError(t, err)
+	require.True(t, lines.Scan())
+	require.Contains(t, lines.Text(), `"result":"inserted"`)
+
+	require.NoError(t, pw.Close())
+	require.False(t, lines.Scan())
+}
+
+func TestExportOrdersCSV(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/orders/export?columns=order_uid,item.name,item.price")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Equal(t, `attachment; filename="orders.csv"`, resp.Header.Get("Content-Disposition"))
+	require.Equal(t, "order_uid,item.name,item.price\n"+
+		"a,Mascaras,453\n"+
+		"a,\"Lipstick, red\",100\n"+
+		"b,,\n", string(raw))
+}
+
+func TestExportOrdersNDJSON(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/orders/export?format=ndjson")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var uids []string
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var o entity.OrderResponse
+		require.NoError(t, dec.Decode(&o))
+		uids = append(uids, o.OrderUID)
+	}
+	require.Equal(t, []string{"a", "b"}, uids)
+}
+
+func TestExportOrdersRejectsBadQuery(t *testing.T) {
+	ts := newTestServer(t)
+
+	status, body := get(t, ts.URL+"/orders/export?columns=order_uid,secret")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, body, `unknown column: \"secret\"`)
+
+	status, _ = get(t, ts.URL+"/orders/export?format=xlsx")
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = get(t, ts.URL+"/orders/export?date_from=yesterday")
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status, body = get(t, ts.URL+"/orders/export?customer_id=broken")
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Contains(t, body, `"code": "internal"`)
+}
+
+func TestRoutesRequireRoles(t *testing.T) {
+	cfg := &config.Config{
+		HTTPTimeout: 2 * time.Second, HTTPIdleTimeout: time.Second, HTTPBulkTimeout: 5 * time.Second, HTTPExportTimeout: 5 * time.Second,
+		AuthEnabled: true,
+		AuthAPIKeys: map[string]string{"r": "reader", "w": "writer", "a": "admin"},
+	}
+	srv, err := InitServer(cfg, zap.NewNop(), fakeUseCase{}, health.New(time.Second))
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler)
+	t.Cleanup(ts.Close)
+
+	call := func(method, path, key string) int {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, call(http.MethodGet, "/healthz", ""), "probes stay public")
+	require.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/order/known", ""))
+	require.Equal(t, http.StatusOK, call(http.MethodGet, "/order/known", "r"))
+
+	require.Equal(t, http.StatusForbidden, call(http.MethodDelete, "/admin/cache", "r"))
+	require.Equal(t, http.StatusForbidden, call(http.MethodDelete, "/admin/cache", "w"))
+	require.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/admin/cache", "a"))
+
+	require.Equal(t, http.StatusForbidden, call(http.MethodPost, "/order/known", "r"))
+	require.NotEqual(t, http.StatusForbidden, call(http.MethodPost, "/order/known", "w"))
+}
+
+func TestPIIMaskedBelowUnmaskedRole(t *testing.T) {
+	cfg := &config.Config{
+		HTTPTimeout: 2 * time.Second, HTTPIdleTimeout: time.Second, HTTPBulkTimeout: 5 * time.Second, HTTPExportTimeout: 5 * time.Second,
+		AuthEnabled:       true,
+		AuthAPIKeys:       map[string]string{"r": "reader", "a": "admin"},
+		AuthAnonymousRole: "reader",
+		PIIMaskingEnabled: true,
+		PIIUnmaskedRole:   "admin",
+		PIIMaskPolicy:     map[string]string{"delivery.city": "full"},
+	}
+	srv, err := InitServer(cfg, zap.NewNop(), fakeUseCase{}, health.New(time.Second))
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler)
+	t.Cleanup(ts.Close)
+
+	call := func(path, key string) string {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	for _, key := range []string{"", "r"} {
+		body := call("/order/known", key)
+		require.Contains(t, body, `"name": "T*** T***"`)
+		require.Contains(t, body, `"phone": "+972***0000"`)
+		require.Contains(t, body, `"email": "t***@gmail.com"`)
+		require.Contains(t, body, `"address": "***"`)
+		require.Contains(t, body, `"city": "***"`)
+		require.NotContains(t, body, "Testov")
+	}
+
+	body := call("/order/known", "a")
+	require.Contains(t, body, `"phone": "+9720000000"`)
+	require.Contains(t, body, `"address": "Ploshad Mira 15"`)
+
+	require.Contains(t, call("/orders/export?format=csv&columns=order_uid,delivery.phone", "r"), "b,+972***0000")
+	require.Contains(t, call("/orders/export?format=csv&columns=order_uid,delivery.phone", "a"), "b,+9720000000")
+}
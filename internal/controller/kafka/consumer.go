@@ -2,10 +2,11 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/RozmiDan/wb_tech_testtask/internal/codec"
 	"github.com/RozmiDan/wb_tech_testtask/internal/config"
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
 	"github.com/google/uuid"
@@ -15,12 +16,32 @@ import (
 
 type OrderHandler interface {
 	AddOrderInfo(ctx context.Context, order *entity.OrderInfo) error
+	UpdateOrderStatus(ctx context.Context, upd *entity.StatusUpdate, source string) (*entity.StatusChange, error)
+	SaveFailedOrder(ctx context.Context, failed *entity.FailedOrder) error
+}
+
+// messageReader — часть *kafka.Reader, которой пользуется консьюмер.
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Stats() kafka.ReaderStats
+	Close() error
 }
 
 type Consumer struct {
-	reader  *kafka.Reader
-	handler OrderHandler
-	logger  *zap.Logger
+	reader     messageReader
+	dlq        DLQWriter
+	decoders   *Decoders
+	handler    OrderHandler
+	retry      retryPolicy
+	stats      consumerStats
+	workers    int
+	queueSize  int
+	dispatchBy string
+	brokers    []string
+	topic      string
+	dialer     *kafka.Dialer
+	logger     *zap.Logger
 }
 
 func NewConsumer(cfg *config.Config, handler OrderHandler, logger *zap.Logger) *Consumer {
@@ -31,92 +52,367 @@ func NewConsumer(cfg *config.Config, handler OrderHandler, logger *zap.Logger) *
 		MinBytes: cfg.KafkaMinBytes,
 		MaxBytes: cfg.KafkaMaxBytes,
 	})
-	return &Consumer{
-		reader:  r,
-		handler: handler,
-		logger:  logger.With(zap.String("component", "kafka_consumer"), zap.String("topic", cfg.KafkaTopic)),
+	c := &Consumer{
+		reader:     r,
+		handler:    handler,
+		decoders:   NewDecoders(),
+		retry:      newRetryPolicy(cfg),
+		workers:    max(cfg.KafkaWorkers, 1),
+		queueSize:  max(cfg.KafkaWorkerQueue, 1),
+		dispatchBy: cfg.KafkaDispatchBy,
+		brokers:    cfg.KafkaBrokers,
+		topic:      cfg.KafkaTopic,
+		dialer:     &kafka.Dialer{Timeout: cfg.KafkaDialTimeout},
+		logger:     logger.With(zap.String("component", "kafka_consumer"), zap.String("topic", cfg.KafkaTopic)),
+	}
+	if cfg.KafkaDLQEnabled {
+		c.dlq = newDLQWriter(cfg)
+		c.logger.Info("dead-letter topic enabled", zap.String("dlq_topic", cfg.KafkaDLQTopic))
 	}
+
+	return c
 }
 
 func (c *Consumer) Start(ctx context.Context, cfg *config.Config) {
-	c.logger.Info("starting kafka consumer loop")
-	for {
-		msg, err := c.reader.FetchMessage(ctx)
-		if err != nil {
-			if errors.Is(err, context.Canceled) || ctx.Err() != nil {
-				c.logger.Info("context done, exiting consumer")
-				return
-			}
-			c.logger.Error("fetch message failed", zap.Error(err))
-			continue
+	c.logger.Info("starting kafka consumer loop",
+		zap.Int("workers", c.workers),
+		zap.String("dispatch_by", c.dispatchBy),
+	)
+	c.runPipeline(ctx, cfg.KafkaMsgTimeout)
+}
+
+// processMessage обрабатывает одно сообщение и сообщает, можно ли коммитить его оффсет.
+func (c *Consumer) processMessage(ctx context.Context, msg kafka.Message, msgTimeout time.Duration) bool {
+	// формируем request_id и контекст на обработку одного сообщения
+	reqID := uuid.NewString()
+	ctx = context.WithValue(ctx, entity.RequestIDKey{}, reqID)
+
+	if ct := contentType(msg); ct != codec.ContentTypeJSON {
+		return c.processEncodedOrder(ctx, msg, ct, reqID, msgTimeout)
+	}
+
+	env, err := unwrap(msg)
+	if err != nil {
+		reason := ReasonInvalidJSON
+		if errors.Is(err, errInvalidEnvelope) {
+			reason = ReasonInvalidEnvelope
 		}
+		c.logger.Warn("invalid message, skipping",
+			zap.String("request_id", reqID),
+			zap.String("reason", reason),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+		return c.reject(ctx, msg, reqID, reason, err)
+	}
 
-		// формируем request_id и контекст на обработку одного сообщения
-		reqID := uuid.NewString()
+	switch env.Type {
+	case EventOrderCreated:
+	case EventOrderStatus:
+		return c.processStatusUpdate(ctx, msg, env, reqID, msgTimeout)
+	default:
+		c.logger.Warn("unknown event type, skipping",
+			zap.String("request_id", reqID),
+			zap.String("event_type", env.Type),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+		)
+		return c.reject(ctx, msg, reqID, ReasonUnknownEvent, errors.New("unknown event type "+env.Type))
+	}
+
+	order, err := c.registry().Order(env.SchemaVersion, env.Payload)
+	if err != nil {
+		reason := decodeReason(err)
+		c.logger.Warn("cant decode order payload, skipping",
+			zap.String("request_id", reqID),
+			zap.String("reason", reason),
+			zap.Int("schema_version", env.SchemaVersion),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+		return c.reject(ctx, msg, reqID, reason, err)
+	}
 
-		ctxMsg := context.WithValue(ctx, entity.RequestIDKey{}, reqID)
-		ctxMsg, cancel := context.WithTimeout(ctxMsg, cfg.KafkaMsgTimeout*time.Second)
+	return c.ingestOrder(ctx, msg, order, reqID, msgTimeout)
+}
+
+// ingestOrder валидирует разобранный заказ и сохраняет его с повторами.
+func (c *Consumer) ingestOrder(ctx context.Context, msg kafka.Message, order *entity.OrderInfo, reqID string, msgTimeout time.Duration) bool {
+	if err := order.ValidateOrder(); err != nil {
+		c.logger.Warn("invalid message payload, skipping",
+			zap.String("request_id", reqID),
+			zap.String("order_uid", order.OrderUID),
+			zap.Error(err),
+		)
+		return c.reject(ctx, msg, reqID, ReasonInvalidPayload, err)
+	}
 
-		order := &entity.OrderInfo{}
-		if err := json.Unmarshal(msg.Value, order); err != nil {
-			c.logger.Warn("invalid message json, skipping",
+	attempts, err := c.handleWithRetry(ctx, order.OrderUID, reqID, msgTimeout, func(ctx context.Context) error {
+		return c.handler.AddOrderInfo(ctx, order)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrAlreadyExists):
+			c.logger.Info("order already exists, committing",
 				zap.String("request_id", reqID),
-				zap.Int("partition", msg.Partition),
-				zap.Int64("offset", msg.Offset),
-				zap.Error(err),
+				zap.String("order_uid", order.OrderUID),
 			)
-			_ = c.reader.CommitMessages(ctx, msg)
-			cancel()
-			continue
-		}
-
-		err = order.ValidateOrder()
-		if err != nil {
-			c.logger.Warn("invalid message payload, skipping",
+			return true
+		case errors.Is(err, entity.ErrInvalidInput):
+			return c.reject(ctx, msg, reqID, ReasonInvalidPayload, err)
+		case ctx.Err() != nil:
+			c.logger.Info("context done while retrying, not committing",
 				zap.String("request_id", reqID),
 				zap.String("order_uid", order.OrderUID),
-				zap.Error(err),
 			)
-			_ = c.reader.CommitMessages(ctx, msg)
-			cancel()
-			continue
+			return false
+		default:
+			c.stats.failed.Add(1)
+			return c.park(ctx, msg, order.OrderUID, reqID, attempts, err)
 		}
+	}
 
-		if err := c.handler.AddOrderInfo(ctxMsg, order); err != nil {
-			switch {
-			case errors.Is(err, entity.ErrAlreadyExists):
-				c.logger.Info("order already exists, committing",
-					zap.String("request_id", reqID),
-					zap.String("order_uid", order.OrderUID),
-				)
-				_ = c.reader.CommitMessages(ctx, msg)
-			default:
-				c.logger.Error("handler failed, will retry (no commit)",
-					zap.String("request_id", reqID),
-					zap.String("order_uid", order.OrderUID),
-					zap.Error(err),
-				)
-			}
-			cancel()
-			continue
+	c.logger.Info("order ingested",
+		zap.String("request_id", reqID),
+		zap.String("order_uid", order.OrderUID),
+		zap.Int("attempts", attempts),
+	)
+	return true
+}
+
+// registry возвращает реестр декодеров консьюмера или реестр по умолчанию.
+func (c *Consumer) registry() *Decoders {
+	if c.decoders == nil {
+		return defaultDecoders
+	}
+	return c.decoders
+}
+
+// decodeReason сопоставляет ошибку декодирования payload с причиной отклонения.
+func decodeReason(err error) string {
+	if errors.Is(err, errUnsupportedVersion) {
+		return ReasonUnsupportedVersion
+	}
+	return ReasonInvalidJSON
+}
+
+// handleWithRetry вызывает обработчик с экспоненциальной задержкой между попытками.
+// Повторяются только внутренние ошибки; дубликаты и невалидный ввод возвращаются сразу.
+// Возвращает число сделанных попыток и последнюю ошибку.
+func (c *Consumer) handleWithRetry(ctx context.Context, orderUID, reqID string, msgTimeout time.Duration, handle func(ctx context.Context) error) (int, error) {
+	started := time.Now()
+	for attempt := 1; ; attempt++ {
+		ctxMsg, cancel := context.WithTimeout(ctx, msgTimeout)
+		err := handle(ctxMsg)
+		cancel()
+
+		if err == nil || permanent(err) {
+			return attempt, err
+		}
+		if ctx.Err() != nil {
+			return attempt, ctx.Err()
 		}
 
-		if err := c.reader.CommitMessages(ctx, msg); err != nil {
-			c.logger.Warn("commit failed",
+		delay := c.retry.backoff(attempt)
+		if !c.retry.allow(attempt, time.Since(started), delay) {
+			c.logger.Error("handler failed, retries exhausted",
 				zap.String("request_id", reqID),
-				zap.String("order_uid", order.OrderUID),
+				zap.String("order_uid", orderUID),
+				zap.Int("attempts", attempt),
+				zap.Duration("elapsed", time.Since(started)),
 				zap.Error(err),
 			)
-		} else {
-			c.logger.Info("order ingested",
+			return attempt, err
+		}
+
+		c.stats.retried.Add(1)
+		c.logger.Warn("handler failed, retrying",
+			zap.String("request_id", reqID),
+			zap.String("order_uid", orderUID),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// permanent сообщает, что повтор не изменит результат обработки.
+func permanent(err error) bool {
+	return errors.Is(err, entity.ErrAlreadyExists) ||
+		errors.Is(err, entity.ErrInvalidInput) ||
+		errors.Is(err, entity.ErrInvalidTransition) ||
+		errors.Is(err, entity.ErrorOrderNotFound)
+}
+
+// park откладывает сообщение, которое не удалось обработать после всех повторов:
+// в DLQ, если он включён, иначе в таблицу failed_orders. Как и в reject, запись
+// повторяется до успеха, после чего оффсет можно коммитить; false — только если
+// контекст завершён.
+func (c *Consumer) park(ctx context.Context, msg kafka.Message, orderUID, reqID string, attempts int, cause error) bool {
+	err := c.retryUntilDone(ctx, msg, reqID, "park failed message", func(ctx context.Context) error {
+		if c.dlq != nil {
+			return c.deadLetter(ctx, msg, reqID, ReasonRetriesExhausted, cause)
+		}
+		return c.handler.SaveFailedOrder(ctx, &entity.FailedOrder{
+			OrderUID:  orderUID,
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Payload:   msg.Value,
+			Reason:    ReasonRetriesExhausted,
+			Error:     cause.Error(),
+			Attempts:  attempts,
+			RequestID: reqID,
+			FailedAt:  time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		c.logger.Info("context done before message was parked, not committing",
+			zap.String("request_id", reqID),
+			zap.String("order_uid", orderUID),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+		)
+		return false
+	}
+
+	c.stats.parked.Add(1)
+	c.logger.Warn("message parked after failed retries",
+		zap.String("request_id", reqID),
+		zap.String("order_uid", orderUID),
+		zap.Int("partition", msg.Partition),
+		zap.Int64("offset", msg.Offset),
+		zap.Int("attempts", attempts),
+	)
+	return true
+}
+
+// reject отправляет отклонённое сообщение в DLQ (если он включён).
+// kafka-go не выдаёт прочитанное сообщение повторно, а следующий коммит партиции
+// перешагнёт через него, поэтому публикация повторяется до успеха. false —
+// только если контекст завершён: тогда оффсет не коммитится и сообщение
+// придёт снова после рестарта.
+func (c *Consumer) reject(ctx context.Context, msg kafka.Message, reqID, reason string, cause error) bool {
+	c.stats.rejected.Add(1)
+	if c.dlq != nil {
+		err := c.retryUntilDone(ctx, msg, reqID, "dead-letter publish", func(ctx context.Context) error {
+			return c.deadLetter(ctx, msg, reqID, reason, cause)
+		})
+		if err != nil {
+			c.logger.Info("context done before dead-letter publish, not committing",
 				zap.String("request_id", reqID),
-				zap.String("order_uid", order.OrderUID),
+				zap.String("reason", reason),
+				zap.Int("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
 			)
+			return false
 		}
-		cancel()
+		c.logger.Info("message moved to dead-letter topic",
+			zap.String("request_id", reqID),
+			zap.String("reason", reason),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+		)
 	}
+
+	return true
+}
+
+// retryUntilDone повторяет fn с экспоненциальной задержкой без лимита попыток,
+// пока она не выполнится или не завершится ctx. Используется там, где сообщение
+// нельзя ни потерять, ни пропустить: оффсеты партиции стоят, пока fn не пройдёт.
+func (c *Consumer) retryUntilDone(ctx context.Context, msg kafka.Message, reqID, what string, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delay := c.retry.backoff(attempt)
+		c.stats.sinkRetried.Add(1)
+		c.logger.Error(what+" failed, retrying",
+			zap.String("request_id", reqID),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Consumer) commit(ctx context.Context, msg kafka.Message) bool {
+	if err := c.reader.CommitMessages(ctx, msg); err != nil {
+		c.logger.Warn("commit failed",
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+		return false
+	}
+	c.stats.committed.Add(1)
+	return true
+}
+
+// Ping проверяет, что брокер отвечает на запрос метаданных топика и у каждой
+// партиции есть лидер: одного TCP-соединения мало, консьюмер без лидеров
+// партиций ничего не прочитает.
+func (c *Consumer) Ping(ctx context.Context) error {
+	if len(c.brokers) == 0 {
+		return errors.New("no kafka brokers configured")
+	}
+
+	var lastErr error
+	for _, b := range c.brokers {
+		parts, err := c.dialer.LookupPartitions(ctx, "tcp", b, c.topic)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return checkPartitions(c.topic, parts)
+	}
+	return lastErr
+}
+
+// checkPartitions проверяет метаданные топика: партиции есть и у всех есть лидер.
+func checkPartitions(topic string, parts []kafka.Partition) error {
+	if len(parts) == 0 {
+		return fmt.Errorf("topic %q has no partitions", topic)
+	}
+	for _, p := range parts {
+		if p.Leader.Host == "" {
+			return fmt.Errorf("topic %q partition %d has no leader", topic, p.ID)
+		}
+	}
+	return nil
 }
 
 func (c *Consumer) Close() error {
+	if c.dlq != nil {
+		if err := c.dlq.Close(); err != nil {
+			c.logger.Warn("dead-letter writer close failed", zap.Error(err))
+		}
+	}
 	return c.reader.Close()
 }
@@ -0,0 +1,18 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPartitions(t *testing.T) {
+	t.Parallel()
+
+	leader := kafka.Broker{Host: "kafka", Port: 9092, ID: 1}
+	require.NoError(t, checkPartitions("orders", []kafka.Partition{{ID: 0, Leader: leader}, {ID: 1, Leader: leader}}))
+	require.ErrorContains(t, checkPartitions("orders", nil), "no partitions")
+	require.ErrorContains(t, checkPartitions("orders", []kafka.Partition{{ID: 0, Leader: leader}, {ID: 1, Leader: kafka.Broker{ID: 2}}}),
+		"partition 1 has no leader")
+}
@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/config"
+	"github.com/segmentio/kafka-go"
+)
+
+// причины отклонения сообщения, попадают в заголовок dlq-reason
+const (
+	ReasonInvalidJSON      = "invalid_json"
+	ReasonInvalidPayload   = "invalid_payload"
+	ReasonRetriesExhausted = "retries_exhausted"
+)
+
+// заголовки, которыми помечается сообщение в dead-letter топике
+const (
+	HeaderDLQReason            = "dlq-reason"
+	HeaderDLQError             = "dlq-error"
+	HeaderDLQOriginalTopic     = "dlq-original-topic"
+	HeaderDLQOriginalPartition = "dlq-original-partition"
+	HeaderDLQOriginalOffset    = "dlq-original-offset"
+	HeaderDLQRequestID         = "dlq-request-id"
+	HeaderDLQRejectedAt        = "dlq-rejected-at"
+)
+
+// DLQWriter публикует отклонённые сообщения в dead-letter топик.
+// *kafka.Writer удовлетворяет интерфейсу, в тестах подменяется in-memory реализацией.
+type DLQWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+func newDLQWriter(cfg *config.Config) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.KafkaBrokers...),
+		Topic:                  cfg.KafkaDLQTopic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: false,
+		BatchTimeout:           50 * time.Millisecond,
+	}
+}
+
+// deadLetter перекладывает исходное сообщение в DLQ, сохраняя ключ, значение и заголовки,
+// и дописывает заголовки с причиной отклонения и координатами оригинала.
+func (c *Consumer) deadLetter(ctx context.Context, msg kafka.Message, reqID, reason string, cause error) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+7)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQReason, Value: []byte(reason)},
+		kafka.Header{Key: HeaderDLQOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQRequestID, Value: []byte(reqID)},
+		kafka.Header{Key: HeaderDLQRejectedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	)
+	if cause != nil {
+		headers = append(headers, kafka.Header{Key: HeaderDLQError, Value: []byte(cause.Error())})
+	}
+
+	return c.dlq.WriteMessages(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+}
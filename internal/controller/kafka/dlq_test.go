@@ -0,0 +1,128 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memorySink — in-memory DLQWriter для тестов
+type memorySink struct {
+	mu     sync.Mutex
+	msgs   []kafka.Message
+	err    error
+	closed bool
+	// failN — сколько первых публикаций завершаются ошибкой err
+	failN int
+}
+
+func (s *memorySink) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil && s.failN != 0 {
+		s.failN--
+		return s.err
+	}
+	s.msgs = append(s.msgs, msgs...)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func headerMap(msg kafka.Message) map[string]string {
+	out := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		out[h.Key] = string(h.Value)
+	}
+	return out
+}
+
+func TestDeadLetterCarriesHeaders(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{}
+	c := &Consumer{dlq: sink, logger: zap.NewNop()}
+
+	orig := kafka.Message{
+		Topic:     "orders",
+		Partition: 3,
+		Offset:    42,
+		Key:       []byte("uid-1"),
+		Value:     []byte("{broken"),
+		Headers:   []kafka.Header{{Key: "trace", Value: []byte("abc")}},
+	}
+
+	err := c.deadLetter(context.Background(), orig, "req-1", ReasonInvalidJSON, errors.New("unexpected EOF"))
+	require.NoError(t, err)
+	require.Len(t, sink.msgs, 1)
+
+	got := sink.msgs[0]
+	require.Equal(t, orig.Key, got.Key)
+	require.Equal(t, orig.Value, got.Value)
+
+	h := headerMap(got)
+	require.Equal(t, "abc", h["trace"])
+	require.Equal(t, ReasonInvalidJSON, h[HeaderDLQReason])
+	require.Equal(t, "unexpected EOF", h[HeaderDLQError])
+	require.Equal(t, "orders", h[HeaderDLQOriginalTopic])
+	require.Equal(t, "3", h[HeaderDLQOriginalPartition])
+	require.Equal(t, "42", h[HeaderDLQOriginalOffset])
+	require.Equal(t, "req-1", h[HeaderDLQRequestID])
+	require.NotEmpty(t, h[HeaderDLQRejectedAt])
+}
+
+func TestDeadLetterPropagatesWriterError(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{err: errors.New("broker down"), failN: -1}
+	c := &Consumer{dlq: sink, logger: zap.NewNop()}
+
+	err := c.deadLetter(context.Background(), kafka.Message{}, "req-2", ReasonInvalidPayload, nil)
+	require.Error(t, err)
+	require.Empty(t, sink.msgs)
+}
+
+func TestRejectRetriesDeadLetterUntilPublished(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{err: errors.New("broker down"), failN: 3}
+	c := &Consumer{
+		dlq:    sink,
+		retry:  retryPolicy{initialBackoff: time.Millisecond, maxBackoff: time.Millisecond, jitter: rand.Int64N},
+		logger: zap.NewNop(),
+	}
+
+	require.True(t, c.reject(context.Background(), kafka.Message{Offset: 7}, "req-3", ReasonInvalidJSON, errors.New("bad")))
+	require.Len(t, sink.msgs, 1)
+	require.Equal(t, uint64(3), c.stats.sinkRetried.Load())
+	require.Zero(t, c.stats.retried.Load(), "dlq outage is not a handler retry")
+}
+
+func TestRejectStopsOnContextDone(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{err: errors.New("broker down"), failN: -1}
+	c := &Consumer{
+		dlq:    sink,
+		retry:  retryPolicy{initialBackoff: time.Millisecond, maxBackoff: time.Millisecond, jitter: rand.Int64N},
+		logger: zap.NewNop(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.False(t, c.reject(ctx, kafka.Message{Offset: 8}, "req-4", ReasonInvalidJSON, errors.New("bad")),
+		"offset stays uncommitted only when shutting down")
+	require.Empty(t, sink.msgs)
+}
@@ -0,0 +1,195 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/codec"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/segmentio/kafka-go"
+)
+
+// причины отклонения, связанные с конвертом сообщения
+const (
+	ReasonInvalidEnvelope    = "invalid_envelope"
+	ReasonUnsupportedVersion = "unsupported_schema_version"
+)
+
+// LegacySchemaVersion — версия, которой считаются сообщения без конверта:
+// тело такого сообщения — документ заказа или события статуса целиком.
+const LegacySchemaVersion = 1
+
+var (
+	errInvalidEnvelope        = errors.New("invalid envelope")
+	errUnsupportedVersion     = errors.New("unsupported schema version")
+	errUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// Envelope — версионированный конверт входящего сообщения.
+type Envelope struct {
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	ProducedAt    time.Time       `json:"produced_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// unwrap достаёт из сообщения тип события, версию схемы и payload.
+// Сообщение без поля payload считается legacy-документом версии LegacySchemaVersion,
+// тип которого берётся из заголовка event-type.
+func unwrap(msg kafka.Message) (Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(msg.Value, &env); err != nil {
+		return Envelope{}, err
+	}
+
+	headerType := eventType(msg)
+	if len(env.Payload) == 0 {
+		if env.Type != "" || env.SchemaVersion != 0 {
+			return Envelope{}, fmt.Errorf("%w: payload is missing", errInvalidEnvelope)
+		}
+		return Envelope{Type: headerType, SchemaVersion: LegacySchemaVersion, Payload: msg.Value}, nil
+	}
+
+	if env.Type == "" {
+		env.Type = headerType
+	} else if hasEventTypeHeader(msg) && env.Type != headerType {
+		return Envelope{}, fmt.Errorf("%w: type %q does not match %s header %q",
+			errInvalidEnvelope, env.Type, HeaderEventType, headerType)
+	}
+	if env.SchemaVersion <= 0 {
+		return Envelope{}, fmt.Errorf("%w: schema_version must be positive", errInvalidEnvelope)
+	}
+	return env, nil
+}
+
+func hasEventTypeHeader(msg kafka.Message) bool {
+	for _, h := range msg.Headers {
+		if h.Key == HeaderEventType && len(h.Value) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// registry хранит декодеры payload одного типа события по версиям схемы.
+type registry[T any] map[int]func(payload []byte) (T, error)
+
+func (r registry[T]) decode(typ string, version int, payload []byte) (T, error) {
+	dec, ok := r[version]
+	if !ok {
+		var zero T
+		versions := make([]int, 0, len(r))
+		for v := range r {
+			versions = append(versions, v)
+		}
+		slices.Sort(versions)
+		return zero, fmt.Errorf("%w: %s schema_version %d, supported %v", errUnsupportedVersion, typ, version, versions)
+	}
+	return dec(payload)
+}
+
+// Decoders — реестр декодеров payload по типу события и версии схемы.
+// Новая версия схемы продюсера добавляется регистрацией декодера,
+// который приводит её к текущим entity.
+type Decoders struct {
+	orders   registry[*entity.OrderInfo]
+	statuses registry[*entity.StatusUpdate]
+	formats  map[string]func(value []byte) (*entity.OrderInfo, error)
+}
+
+// NewDecoders возвращает реестр со всеми поддерживаемыми версиями.
+func NewDecoders() *Decoders {
+	d := &Decoders{
+		orders:   registry[*entity.OrderInfo]{},
+		statuses: registry[*entity.StatusUpdate]{},
+		formats:  make(map[string]func([]byte) (*entity.OrderInfo, error)),
+	}
+	d.RegisterOrder(1, decodeOrderV1)
+	d.RegisterOrder(2, decodeOrderV2)
+	d.RegisterStatus(1, decodeStatusV1)
+	d.RegisterFormat(codec.ContentTypeProtobuf, codec.DecodeProtobuf)
+	d.RegisterFormat(codec.ContentTypeAvro, codec.DecodeAvro)
+	return d
+}
+
+var defaultDecoders = NewDecoders()
+
+// RegisterOrder регистрирует декодер order.created для версии схемы.
+func (d *Decoders) RegisterOrder(version int, dec func(payload []byte) (*entity.OrderInfo, error)) {
+	d.orders[version] = dec
+}
+
+// RegisterStatus регистрирует декодер order.status для версии схемы.
+func (d *Decoders) RegisterStatus(version int, dec func(payload []byte) (*entity.StatusUpdate, error)) {
+	d.statuses[version] = dec
+}
+
+// RegisterFormat регистрирует декодер order.created для бинарного формата,
+// выбираемого по заголовку content-type. JSON разбирается через конверт и версии схемы.
+func (d *Decoders) RegisterFormat(contentType string, dec func(value []byte) (*entity.OrderInfo, error)) {
+	d.formats[codec.NormalizeContentType(contentType)] = dec
+}
+
+func (d *Decoders) Format(contentType string, value []byte) (*entity.OrderInfo, error) {
+	dec, ok := d.formats[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedContentType, contentType)
+	}
+	return dec(value)
+}
+
+func (d *Decoders) Order(version int, payload []byte) (*entity.OrderInfo, error) {
+	return d.orders.decode(EventOrderCreated, version, payload)
+}
+
+func (d *Decoders) Status(version int, payload []byte) (*entity.StatusUpdate, error) {
+	return d.statuses.decode(EventOrderStatus, version, payload)
+}
+
+func decodeOrderV1(payload []byte) (*entity.OrderInfo, error) {
+	order := &entity.OrderInfo{}
+	if err := json.Unmarshal(payload, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// orderV2 — вторая версия схемы заказа: shardkey переименован в shard_key,
+// payment.payment_dt (unix-секунды) заменён на payment.paid_at (RFC 3339).
+// Новые поля, которых нет в entity.OrderInfo, игнорируются.
+type orderV2 struct {
+	entity.OrderInfo
+	ShardKey string    `json:"shard_key"`
+	Payment  paymentV2 `json:"payment"`
+}
+
+type paymentV2 struct {
+	entity.PaymentInfo
+	PaidAt time.Time `json:"paid_at"`
+}
+
+func decodeOrderV2(payload []byte) (*entity.OrderInfo, error) {
+	var v orderV2
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return nil, err
+	}
+
+	order := v.OrderInfo
+	order.ShardKey = v.ShardKey
+	order.Payment = v.Payment.PaymentInfo
+	if !v.Payment.PaidAt.IsZero() {
+		order.Payment.PaymentDT = v.Payment.PaidAt.Unix()
+	}
+	return &order, nil
+}
+
+func decodeStatusV1(payload []byte) (*entity.StatusUpdate, error) {
+	upd := &entity.StatusUpdate{}
+	if err := json.Unmarshal(payload, upd); err != nil {
+		return nil, err
+	}
+	return upd, nil
+}
@@ -0,0 +1,164 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func envelopeMessage(t *testing.T, typ string, version int, payload any) kafka.Message {
+	t.Helper()
+
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+	value, err := json.Marshal(Envelope{
+		Type:          typ,
+		SchemaVersion: version,
+		ProducedAt:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Payload:       raw,
+	})
+	require.NoError(t, err)
+	return kafka.Message{Value: value}
+}
+
+func TestUnwrapLegacyMessage(t *testing.T) {
+	t.Parallel()
+
+	value, err := json.Marshal(testOrder("legacy"))
+	require.NoError(t, err)
+
+	env, err := unwrap(kafka.Message{Value: value})
+	require.NoError(t, err)
+	require.Equal(t, EventOrderCreated, env.Type)
+	require.Equal(t, LegacySchemaVersion, env.SchemaVersion)
+	require.JSONEq(t, string(value), string(env.Payload))
+
+	env, err = unwrap(kafka.Message{
+		Value:   []byte(`{"order_uid":"legacy","status":"paid"}`),
+		Headers: []kafka.Header{{Key: HeaderEventType, Value: []byte(EventOrderStatus)}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, EventOrderStatus, env.Type)
+}
+
+func TestUnwrapEnvelope(t *testing.T) {
+	t.Parallel()
+
+	env, err := unwrap(envelopeMessage(t, EventOrderCreated, 2, map[string]string{"order_uid": "x"}))
+	require.NoError(t, err)
+	require.Equal(t, EventOrderCreated, env.Type)
+	require.Equal(t, 2, env.SchemaVersion)
+	require.False(t, env.ProducedAt.IsZero())
+
+	cases := map[string]kafka.Message{
+		"missing payload": {Value: []byte(`{"type":"order.created","schema_version":1}`)},
+		"zero version":    {Value: []byte(`{"type":"order.created","payload":{}}`)},
+		"header mismatch": {
+			Value:   []byte(`{"type":"order.created","schema_version":1,"payload":{}}`),
+			Headers: []kafka.Header{{Key: HeaderEventType, Value: []byte(EventOrderStatus)}},
+		},
+	}
+	for name, msg := range cases {
+		_, err := unwrap(msg)
+		require.ErrorIs(t, err, errInvalidEnvelope, name)
+	}
+
+	_, err = unwrap(kafka.Message{Value: []byte(`{broken`)})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errInvalidEnvelope)
+}
+
+func TestDecodeOrderV2(t *testing.T) {
+	t.Parallel()
+
+	paidAt := time.Date(2021, 11, 26, 6, 22, 7, 0, time.UTC)
+	payload := []byte(`{
+		"order_uid": "v2",
+		"shard_key": "9",
+		"payment": {"transaction": "v2", "amount": 1817, "paid_at": "2021-11-26T06:22:07Z"},
+		"sales_channel": "app"
+	}`)
+
+	order, err := NewDecoders().Order(2, payload)
+	require.NoError(t, err)
+	require.Equal(t, "v2", order.OrderUID)
+	require.Equal(t, "9", order.ShardKey)
+	require.Equal(t, "v2", order.Payment.Transaction)
+	require.EqualValues(t, 1817, order.Payment.Amount)
+	require.Equal(t, paidAt.Unix(), order.Payment.PaymentDT)
+}
+
+func TestDecodeUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewDecoders().Order(7, []byte(`{}`))
+	require.ErrorIs(t, err, errUnsupportedVersion)
+	require.Contains(t, err.Error(), "order.created schema_version 7, supported [1 2]")
+
+	_, err = NewDecoders().Status(2, []byte(`{}`))
+	require.ErrorIs(t, err, errUnsupportedVersion)
+}
+
+func TestProcessMessageEnvelopeVersions(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{}
+	handler := &slowHandler{seen: make(map[string]int)}
+	c := &Consumer{
+		handler: handler,
+		dlq:     sink,
+		retry:   retryPolicy{maxAttempts: 1, jitter: rand.Int64N},
+		logger:  zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	legacy, err := json.Marshal(testOrder("legacy"))
+	require.NoError(t, err)
+	require.True(t, c.processMessage(ctx, kafka.Message{Value: legacy}, time.Second))
+	require.True(t, c.processMessage(ctx, envelopeMessage(t, EventOrderCreated, 1, testOrder("enveloped")), time.Second))
+	require.Equal(t, map[string]int{"legacy": 1, "enveloped": 1}, handler.seen)
+	require.Empty(t, sink.msgs)
+
+	// неизвестная версия отклоняется в DLQ с понятной причиной, оффсет коммитится
+	require.True(t, c.processMessage(ctx, envelopeMessage(t, EventOrderCreated, 9, testOrder("future")), time.Second))
+	require.Len(t, sink.msgs, 1)
+	h := headerMap(sink.msgs[0])
+	require.Equal(t, ReasonUnsupportedVersion, h[HeaderDLQReason])
+	require.Contains(t, h[HeaderDLQError], "schema_version 9")
+	require.NotContains(t, handler.seen, "future")
+
+	// payload с неверными типами полей — invalid_json
+	require.True(t, c.processMessage(ctx, kafka.Message{
+		Value: []byte(`{"type":"order.created","schema_version":1,"payload":{"sm_id":"x"}}`),
+	}, time.Second))
+	require.Equal(t, ReasonInvalidJSON, headerMap(sink.msgs[len(sink.msgs)-1])[HeaderDLQReason])
+}
+
+// TestProducerSamplesAreValid прогоняет демо-заказы producer'а через разбор и
+// валидацию консьюмера: новое правило не должно молча отправлять их в DLQ.
+func TestProducerSamplesAreValid(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob("../../../producer_samples/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		value, err := os.ReadFile(file)
+		require.NoError(t, err, file)
+
+		env, err := unwrap(kafka.Message{Value: value})
+		require.NoError(t, err, file)
+		order, err := defaultDecoders.Order(env.SchemaVersion, env.Payload)
+		require.NoError(t, err, file)
+		require.NoError(t, order.ValidateOrder(), file)
+	}
+}
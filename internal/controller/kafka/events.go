@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// HeaderEventType — заголовок с типом события. Сообщения без него считаются order.created.
+const HeaderEventType = "event-type"
+
+// типы событий в топике заказов
+const (
+	EventOrderCreated = entity.EventOrderCreated
+	EventOrderStatus  = "order.status"
+)
+
+// причины отклонения событий смены статуса
+const (
+	ReasonUnknownEvent      = "unknown_event"
+	ReasonInvalidTransition = "invalid_transition"
+	ReasonOrderNotFound     = "order_not_found"
+)
+
+func eventType(msg kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == HeaderEventType && len(h.Value) > 0 {
+			return string(h.Value)
+		}
+	}
+	return EventOrderCreated
+}
+
+// processStatusUpdate применяет событие смены статуса заказа или позиции.
+func (c *Consumer) processStatusUpdate(ctx context.Context, msg kafka.Message, env Envelope, reqID string, msgTimeout time.Duration) bool {
+	upd, err := c.registry().Status(env.SchemaVersion, env.Payload)
+	if err != nil {
+		reason := decodeReason(err)
+		c.logger.Warn("cant decode status event payload, skipping",
+			zap.String("request_id", reqID),
+			zap.String("reason", reason),
+			zap.Int("schema_version", env.SchemaVersion),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+		return c.reject(ctx, msg, reqID, reason, err)
+	}
+
+	attempts, err := c.handleWithRetry(ctx, upd.OrderUID, reqID, msgTimeout, func(ctx context.Context) error {
+		_, err := c.handler.UpdateOrderStatus(ctx, upd, entity.StatusSourceKafka)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidInput):
+			return c.reject(ctx, msg, reqID, ReasonInvalidPayload, err)
+		case errors.Is(err, entity.ErrInvalidTransition):
+			return c.reject(ctx, msg, reqID, ReasonInvalidTransition, err)
+		case errors.Is(err, entity.ErrorOrderNotFound):
+			return c.reject(ctx, msg, reqID, ReasonOrderNotFound, err)
+		case ctx.Err() != nil:
+			c.logger.Info("context done while retrying, not committing",
+				zap.String("request_id", reqID),
+				zap.String("order_uid", upd.OrderUID),
+			)
+			return false
+		default:
+			c.stats.failed.Add(1)
+			return c.park(ctx, msg, upd.OrderUID, reqID, attempts, err)
+		}
+	}
+
+	c.logger.Info("order status event applied",
+		zap.String("request_id", reqID),
+		zap.String("order_uid", upd.OrderUID),
+		zap.String("status", string(upd.Status)),
+		zap.Int("attempts", attempts),
+	)
+	return true
+}
@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/codec"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// HeaderContentType — заголовок с форматом тела сообщения. Без него тело считается JSON.
+const HeaderContentType = "content-type"
+
+// причины отклонения сообщений в бинарных форматах
+const (
+	ReasonUnsupportedContentType = "unsupported_content_type"
+	ReasonInvalidEncoding        = "invalid_encoding"
+)
+
+func contentType(msg kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == HeaderContentType {
+			return codec.NormalizeContentType(string(h.Value))
+		}
+	}
+	return codec.ContentTypeJSON
+}
+
+// processEncodedOrder обрабатывает заказ в бинарном формате (Protobuf, Avro).
+// В таких форматах принимаются только события order.created.
+func (c *Consumer) processEncodedOrder(ctx context.Context, msg kafka.Message, ct, reqID string, msgTimeout time.Duration) bool {
+	if typ := eventType(msg); typ != EventOrderCreated {
+		c.logger.Warn("event type is not supported in binary format, skipping",
+			zap.String("request_id", reqID),
+			zap.String("event_type", typ),
+			zap.String("content_type", ct),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+		)
+		return c.reject(ctx, msg, reqID, ReasonUnsupportedContentType,
+			errors.New(typ+" is accepted only as "+codec.ContentTypeJSON))
+	}
+
+	order, err := c.registry().Format(ct, msg.Value)
+	if err != nil {
+		reason := ReasonInvalidEncoding
+		if errors.Is(err, errUnsupportedContentType) {
+			reason = ReasonUnsupportedContentType
+		}
+		c.logger.Warn("cant decode order, skipping",
+			zap.String("request_id", reqID),
+			zap.String("reason", reason),
+			zap.String("content_type", ct),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+		return c.reject(ctx, msg, reqID, reason, err)
+	}
+
+	return c.ingestOrder(ctx, msg, order, reqID, msgTimeout)
+}
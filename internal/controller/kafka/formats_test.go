@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/codec"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProcessMessageBinaryFormats(t *testing.T) {
+	t.Parallel()
+
+	sink := &memorySink{}
+	handler := &slowHandler{seen: make(map[string]int)}
+	c := &Consumer{
+		handler: handler,
+		dlq:     sink,
+		retry:   retryPolicy{maxAttempts: 1, jitter: rand.Int64N},
+		logger:  zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	for _, ct := range []string{codec.ContentTypeProtobuf, codec.ContentTypeAvro, "application/json; charset=utf-8"} {
+		value, err := codec.Encode(ct, testOrder(ct))
+		require.NoError(t, err)
+		msg := kafka.Message{Value: value, Headers: []kafka.Header{{Key: HeaderContentType, Value: []byte(ct)}}}
+		require.True(t, c.processMessage(ctx, msg, time.Second), ct)
+		require.Equal(t, 1, handler.seen[ct], ct)
+	}
+	require.Empty(t, sink.msgs)
+
+	reject := func(msg kafka.Message) string {
+		t.Helper()
+		before := len(sink.msgs)
+		require.True(t, c.processMessage(ctx, msg, time.Second))
+		require.Len(t, sink.msgs, before+1)
+		return headerMap(sink.msgs[before])[HeaderDLQReason]
+	}
+
+	require.Equal(t, ReasonUnsupportedContentType, reject(kafka.Message{
+		Value:   []byte("a,b,c"),
+		Headers: []kafka.Header{{Key: HeaderContentType, Value: []byte("text/csv")}},
+	}))
+	require.Equal(t, ReasonInvalidEncoding, reject(kafka.Message{
+		Value:   []byte(`{"order_uid":"json-in-avro"}`),
+		Headers: []kafka.Header{{Key: HeaderContentType, Value: []byte(codec.ContentTypeAvro)}},
+	}))
+	require.Equal(t, ReasonUnsupportedContentType, reject(kafka.Message{
+		Value: []byte{0x0a, 0x01, 0x78},
+		Headers: []kafka.Header{
+			{Key: HeaderContentType, Value: []byte(codec.ContentTypeProtobuf)},
+			{Key: HeaderEventType, Value: []byte(EventOrderStatus)},
+		},
+	}))
+}
@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker следит за оффсетами, которые выданы воркерам, и определяет,
+// до какого сообщения в партиции можно безопасно закоммитить: коммитится только
+// непрерывный префикс обработанных оффсетов.
+type offsetTracker struct {
+	mu         sync.RWMutex
+	partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	mu       sync.Mutex
+	inflight []int64                 // оффсеты в порядке получения
+	done     map[int64]kafka.Message // обработанные, но ещё не закоммиченные
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[int]*partitionOffsets)}
+}
+
+func (t *offsetTracker) partition(p int) *partitionOffsets {
+	t.mu.RLock()
+	po, ok := t.partitions[p]
+	t.mu.RUnlock()
+	if ok {
+		return po
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if po, ok = t.partitions[p]; !ok {
+		po = &partitionOffsets{done: make(map[int64]kafka.Message)}
+		t.partitions[p] = po
+	}
+	return po
+}
+
+// track регистрирует полученное сообщение. Если оффсет не больше уже отслеживаемых,
+// значит группа перебалансировалась и партиция читается заново — состояние сбрасывается.
+func (t *offsetTracker) track(msg kafka.Message) {
+	po := t.partition(msg.Partition)
+
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	if n := len(po.inflight); n > 0 && msg.Offset <= po.inflight[n-1] {
+		po.inflight = po.inflight[:0]
+		clear(po.done)
+	}
+	po.inflight = append(po.inflight, msg.Offset)
+}
+
+// complete отмечает сообщение обработанным. Если непрерывный префикс вырос,
+// commit вызывается с последним сообщением префикса под блокировкой партиции,
+// чтобы коммиты внутри партиции шли строго по возрастанию.
+func (t *offsetTracker) complete(msg kafka.Message, commit func(kafka.Message)) {
+	po := t.partition(msg.Partition)
+
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	po.done[msg.Offset] = msg
+
+	var (
+		last  kafka.Message
+		ready bool
+	)
+	for len(po.inflight) > 0 {
+		m, ok := po.done[po.inflight[0]]
+		if !ok {
+			break
+		}
+		delete(po.done, po.inflight[0])
+		po.inflight = po.inflight[1:]
+		last, ready = m, true
+	}
+	if ready {
+		commit(last)
+	}
+}
+
+// pending возвращает число сообщений партиции, ожидающих коммита.
+func (t *offsetTracker) pending(p int) int {
+	po := t.partition(p)
+
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return len(po.inflight)
+}
@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// режимы распределения сообщений по воркерам
+const (
+	DispatchByPartition = "partition"
+	DispatchByKey       = "key"
+)
+
+// runPipeline читает сообщения и раздаёт их пулу воркеров. Порядок сохраняется
+// внутри партиции (или внутри ключа = order_uid), разные партиции обрабатываются
+// параллельно. Коммитятся только непрерывно обработанные оффсеты.
+func (c *Consumer) runPipeline(ctx context.Context, msgTimeout time.Duration) {
+	tracker := newOffsetTracker()
+	queues := make([]chan kafka.Message, c.workers)
+
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, c.queueSize)
+		wg.Add(1)
+		go func(id int, in <-chan kafka.Message) {
+			defer wg.Done()
+			c.worker(ctx, id, in, tracker, msgTimeout)
+		}(i, queues[i])
+	}
+	defer func() {
+		for _, q := range queues {
+			close(q)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.logger.Info("context done, exiting consumer")
+				return
+			}
+			c.logger.Error("fetch message failed", zap.Error(err))
+			continue
+		}
+		c.stats.consumed.Add(1)
+		tracker.track(msg)
+
+		select {
+		case queues[c.dispatch(msg)] <- msg:
+		case <-ctx.Done():
+			c.logger.Info("context done, exiting consumer")
+			return
+		}
+	}
+}
+
+func (c *Consumer) worker(ctx context.Context, id int, in <-chan kafka.Message, tracker *offsetTracker, msgTimeout time.Duration) {
+	logger := c.logger.With(zap.Int("worker", id))
+	for msg := range in {
+		if ctx.Err() != nil {
+			// сообщения из очереди не коммитим — после рестарта они придут снова
+			continue
+		}
+		// processMessage возвращает false, только если контекст завершён:
+		// DLQ и парковка повторяются до успеха, поэтому при работающем
+		// консьюмере каждое сообщение отмечается обработанным и партиция не встаёт
+		if !c.processMessage(ctx, msg, msgTimeout) {
+			logger.Info("consumer stopping, message left uncommitted",
+				zap.Int("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
+			)
+			continue
+		}
+		tracker.complete(msg, func(m kafka.Message) {
+			c.commit(ctx, m)
+		})
+	}
+}
+
+// dispatch выбирает воркера для сообщения.
+func (c *Consumer) dispatch(msg kafka.Message) int {
+	if c.workers == 1 {
+		return 0
+	}
+	if c.dispatchBy == DispatchByKey && len(msg.Key) > 0 {
+		h := fnv.New32a()
+		_, _ = h.Write(msg.Key)
+		return int(h.Sum32() % uint32(c.workers))
+	}
+	return msg.Partition % c.workers
+}
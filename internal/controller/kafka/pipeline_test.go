@@ -0,0 +1,296 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/config"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeReader отдаёт заранее подготовленные сообщения и запоминает коммиты
+type fakeReader struct {
+	msgs chan kafka.Message
+
+	mu      sync.Mutex
+	commits map[int][]int64
+}
+
+func newFakeReader(msgs []kafka.Message) *fakeReader {
+	ch := make(chan kafka.Message, len(msgs))
+	for _, m := range msgs {
+		ch <- m
+	}
+	return &fakeReader{msgs: ch, commits: make(map[int][]int64)}
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.msgs:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range msgs {
+		r.commits[m.Partition] = append(r.commits[m.Partition], m.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Stats() kafka.ReaderStats { return kafka.ReaderStats{} }
+
+func (r *fakeReader) Close() error { return nil }
+
+func (r *fakeReader) lastCommit(p int) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.commits[p]
+	if len(c) == 0 {
+		return -1
+	}
+	return c[len(c)-1]
+}
+
+// slowHandler имитирует запись в БД со случайной задержкой
+type slowHandler struct {
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+func (h *slowHandler) AddOrderInfo(_ context.Context, order *entity.OrderInfo) error {
+	time.Sleep(time.Duration(rand.IntN(2000)) * time.Microsecond)
+	h.mu.Lock()
+	h.seen[order.OrderUID]++
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *slowHandler) UpdateOrderStatus(context.Context, *entity.StatusUpdate, string) (*entity.StatusChange, error) {
+	return nil, nil
+}
+
+func (h *slowHandler) SaveFailedOrder(context.Context, *entity.FailedOrder) error { return nil }
+
+func testOrder(uid string) *entity.OrderInfo {
+	return &entity.OrderInfo{
+		OrderUID:        uid,
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		ShardKey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+		Delivery: entity.DeliveryInfo{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: entity.PaymentInfo{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDT:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []entity.ItemInfo{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			Rid:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+	}
+}
+
+func TestPipelineCommitsContiguousOffsets(t *testing.T) {
+	t.Parallel()
+
+	const (
+		partitions = 4
+		perPart    = 50
+	)
+	var msgs []kafka.Message
+	for off := int64(0); off < perPart; off++ {
+		for p := 0; p < partitions; p++ {
+			uid := fmt.Sprintf("order-%d-%d", p, off)
+			val, err := json.Marshal(testOrder(uid))
+			require.NoError(t, err)
+			msgs = append(msgs, kafka.Message{Topic: "orders", Partition: p, Offset: off, Key: []byte(uid), Value: val})
+		}
+	}
+
+	for _, mode := range []string{DispatchByPartition, DispatchByKey} {
+		t.Run(mode, func(t *testing.T) {
+			t.Parallel()
+
+			reader := newFakeReader(msgs)
+			handler := &slowHandler{seen: make(map[string]int)}
+			c := &Consumer{
+				reader:     reader,
+				handler:    handler,
+				retry:      retryPolicy{maxAttempts: 1, jitter: rand.Int64N},
+				workers:    3,
+				queueSize:  8,
+				dispatchBy: mode,
+				logger:     zap.NewNop(),
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				c.Start(ctx, &config.Config{KafkaMsgTimeout: time.Second})
+				close(done)
+			}()
+
+			require.Eventually(t, func() bool {
+				for p := 0; p < partitions; p++ {
+					if reader.lastCommit(p) != perPart-1 {
+						return false
+					}
+				}
+				return true
+			}, 5*time.Second, 5*time.Millisecond)
+
+			cancel()
+			<-done
+
+			reader.mu.Lock()
+			defer reader.mu.Unlock()
+			for p, offsets := range reader.commits {
+				for i := 1; i < len(offsets); i++ {
+					require.Greater(t, offsets[i], offsets[i-1], "commits of partition %d must grow", p)
+				}
+			}
+			require.Len(t, handler.seen, partitions*perPart)
+			require.Equal(t, uint64(partitions*perPart), c.Stats().Consumed)
+		})
+	}
+}
+
+func TestOffsetTrackerWaitsForGaps(t *testing.T) {
+	t.Parallel()
+
+	tr := newOffsetTracker()
+	for off := int64(10); off < 13; off++ {
+		tr.track(kafka.Message{Partition: 0, Offset: off})
+	}
+
+	var committed []int64
+	commit := func(m kafka.Message) { committed = append(committed, m.Offset) }
+
+	tr.complete(kafka.Message{Partition: 0, Offset: 12}, commit)
+	tr.complete(kafka.Message{Partition: 0, Offset: 11}, commit)
+	require.Empty(t, committed, "offset 10 is still in flight")
+	require.Equal(t, 3, tr.pending(0))
+
+	tr.complete(kafka.Message{Partition: 0, Offset: 10}, commit)
+	require.Equal(t, []int64{12}, committed)
+	require.Equal(t, 0, tr.pending(0))
+
+	// повторная выдача после ребаланса сбрасывает состояние партиции
+	tr.track(kafka.Message{Partition: 0, Offset: 13})
+	tr.track(kafka.Message{Partition: 0, Offset: 11})
+	require.Equal(t, 1, tr.pending(0))
+}
+
+func TestPipelineKeepsCommittingWhileDLQFails(t *testing.T) {
+	t.Parallel()
+
+	good, err := json.Marshal(testOrder("good-1"))
+	require.NoError(t, err)
+	msgs := []kafka.Message{
+		{Topic: "orders", Partition: 0, Offset: 0, Value: []byte("{broken")},
+		{Topic: "orders", Partition: 0, Offset: 1, Value: good},
+	}
+
+	reader := newFakeReader(msgs)
+	sink := &memorySink{err: errors.New("broker down"), failN: 5}
+	handler := &slowHandler{seen: make(map[string]int)}
+	c := &Consumer{
+		reader:    reader,
+		dlq:       sink,
+		handler:   handler,
+		retry:     retryPolicy{maxAttempts: 1, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond, jitter: rand.Int64N},
+		workers:   1,
+		queueSize: 4,
+		logger:    zap.NewNop(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Start(ctx, &config.Config{KafkaMsgTimeout: time.Second})
+		close(done)
+	}()
+
+	// битое сообщение доходит до DLQ после повторов, и партиция коммитится дальше
+	require.Eventually(t, func() bool { return reader.lastCommit(0) == 1 }, 5*time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	sink.mu.Lock()
+	require.Len(t, sink.msgs, 1)
+	sink.mu.Unlock()
+	require.Equal(t, 1, handler.seen["good-1"])
+}
+
+func TestPipelineHoldsOffsetWhenStoppedDuringDLQOutage(t *testing.T) {
+	t.Parallel()
+
+	reader := newFakeReader([]kafka.Message{{Topic: "orders", Partition: 0, Offset: 0, Value: []byte("{broken")}})
+	sink := &memorySink{err: errors.New("broker down"), failN: -1}
+	c := &Consumer{
+		reader:    reader,
+		dlq:       sink,
+		handler:   &slowHandler{seen: make(map[string]int)},
+		retry:     retryPolicy{maxAttempts: 1, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond, jitter: rand.Int64N},
+		workers:   1,
+		queueSize: 4,
+		logger:    zap.NewNop(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Start(ctx, &config.Config{KafkaMsgTimeout: time.Second})
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return c.stats.sinkRetried.Load() >= 3 }, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	// сообщение не потеряно: после рестарта группа прочитает его снова
+	require.Equal(t, int64(-1), reader.lastCommit(0))
+	require.Empty(t, sink.msgs)
+}
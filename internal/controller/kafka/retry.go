@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/config"
+)
+
+// retryPolicy описывает ограниченные повторы обработчика:
+// экспоненциальная задержка с джиттером, лимит попыток и лимит общего времени.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxElapsed     time.Duration
+	jitter         func(n int64) int64
+}
+
+func newRetryPolicy(cfg *config.Config) retryPolicy {
+	p := retryPolicy{
+		maxAttempts:    cfg.KafkaRetryMaxAttempts,
+		initialBackoff: cfg.KafkaRetryInitialBackoff,
+		maxBackoff:     cfg.KafkaRetryMaxBackoff,
+		maxElapsed:     cfg.KafkaRetryMaxElapsed,
+		jitter:         rand.Int64N,
+	}
+	if p.maxAttempts <= 0 {
+		p.maxAttempts = 1
+	}
+	if p.initialBackoff <= 0 {
+		p.initialBackoff = 100 * time.Millisecond
+	}
+	if p.maxBackoff < p.initialBackoff {
+		p.maxBackoff = p.initialBackoff
+	}
+	return p
+}
+
+// backoff возвращает задержку перед следующей попыткой после attempt неудачных (attempt >= 1).
+// Используется "equal jitter": половина задержки фиксирована, половина случайна.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(p.jitter(int64(half)+1))
+}
+
+// allow сообщает, можно ли сделать ещё одну попытку после attempt неудачных,
+// учитывая уже потраченное время и следующую задержку.
+func (p retryPolicy) allow(attempt int, elapsed, next time.Duration) bool {
+	if attempt >= p.maxAttempts {
+		return false
+	}
+	if p.maxElapsed > 0 && elapsed+next > p.maxElapsed {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRetryBackoffGrowsAndCaps(t *testing.T) {
+	t.Parallel()
+
+	p := retryPolicy{
+		maxAttempts:    10,
+		initialBackoff: 100 * time.Millisecond,
+		maxBackoff:     time.Second,
+		jitter:         func(n int64) int64 { return n - 1 }, // максимальный джиттер
+	}
+
+	require.Equal(t, 100*time.Millisecond, p.backoff(1))
+	require.Equal(t, 200*time.Millisecond, p.backoff(2))
+	require.Equal(t, 400*time.Millisecond, p.backoff(3))
+	require.Equal(t, time.Second, p.backoff(5))
+	require.Equal(t, time.Second, p.backoff(50))
+
+	p.jitter = func(int64) int64 { return 0 } // минимальный джиттер
+	require.Equal(t, 50*time.Millisecond, p.backoff(1))
+	require.Equal(t, 500*time.Millisecond, p.backoff(50))
+}
+
+func TestRetryAllowLimits(t *testing.T) {
+	t.Parallel()
+
+	p := retryPolicy{maxAttempts: 3, maxElapsed: time.Second}
+
+	require.True(t, p.allow(1, 0, 100*time.Millisecond))
+	require.True(t, p.allow(2, 500*time.Millisecond, 400*time.Millisecond))
+	require.False(t, p.allow(3, 0, 0), "attempts exhausted")
+	require.False(t, p.allow(2, 800*time.Millisecond, 300*time.Millisecond), "elapsed exhausted")
+
+	p.maxElapsed = 0
+	require.True(t, p.allow(2, time.Hour, time.Hour), "zero max elapsed disables the limit")
+}
+
+// failingHandler всегда падает с внутренней ошибкой, а парковка удаётся
+// только после parkFails неудачных попыток
+type failingHandler struct {
+	mu        sync.Mutex
+	adds      int
+	parkFails int
+	parked    []*entity.FailedOrder
+}
+
+func (h *failingHandler) AddOrderInfo(context.Context, *entity.OrderInfo) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.adds++
+	return entity.ErrInternal
+}
+
+func (h *failingHandler) UpdateOrderStatus(context.Context, *entity.StatusUpdate, string) (*entity.StatusChange, error) {
+	return nil, entity.ErrInternal
+}
+
+func (h *failingHandler) SaveFailedOrder(_ context.Context, f *entity.FailedOrder) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.parkFails > 0 {
+		h.parkFails--
+		return errors.New("db down")
+	}
+	h.parked = append(h.parked, f)
+	return nil
+}
+
+func TestRetriesExhaustedParksUntilSaved(t *testing.T) {
+	t.Parallel()
+
+	handler := &failingHandler{parkFails: 2}
+	c := &Consumer{
+		handler: handler,
+		retry:   retryPolicy{maxAttempts: 3, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond, jitter: rand.Int64N},
+		logger:  zap.NewNop(),
+	}
+
+	val, err := json.Marshal(testOrder("parked-1"))
+	require.NoError(t, err)
+	msg := kafka.Message{Topic: "orders", Partition: 1, Offset: 5, Value: val}
+
+	require.True(t, c.processMessage(context.Background(), msg, time.Second), "parked message can be committed")
+	require.Equal(t, 3, handler.adds)
+	require.Len(t, handler.parked, 1)
+	require.Equal(t, "parked-1", handler.parked[0].OrderUID)
+	require.Equal(t, int64(5), handler.parked[0].Offset)
+	require.Equal(t, 3, handler.parked[0].Attempts)
+	require.Equal(t, uint64(1), c.stats.parked.Load())
+	require.Equal(t, uint64(2), c.stats.retried.Load(), "handler retries")
+	require.Equal(t, uint64(2), c.stats.sinkRetried.Load(), "park retries are counted apart")
+}
+
+func TestParkGivesUpOnlyOnContextDone(t *testing.T) {
+	t.Parallel()
+
+	handler := &failingHandler{parkFails: 1 << 30}
+	c := &Consumer{
+		handler: handler,
+		retry:   retryPolicy{maxAttempts: 1, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond, jitter: rand.Int64N},
+		logger:  zap.NewNop(),
+	}
+
+	val, err := json.Marshal(testOrder("parked-2"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.False(t, c.processMessage(ctx, kafka.Message{Value: val}, time.Second))
+	require.Empty(t, handler.parked)
+}
@@ -0,0 +1,49 @@
+package kafka
+
+import "sync/atomic"
+
+// ConsumerStats — снимок счётчиков консьюмера с момента старта
+// и текущих значений lag/offset из kafka.Reader.Stats().
+type ConsumerStats struct {
+	Consumed  uint64
+	Committed uint64
+	Rejected  uint64
+	Retried   uint64
+	Parked    uint64
+	Failed    uint64
+	// SinkRetried — повторы отправки в DLQ и парковки, пока они недоступны;
+	// в Retried не входят, это не повторы обработки заказа
+	SinkRetried uint64
+
+	Lag         int64
+	Offset      int64
+	QueueLength int64
+}
+
+type consumerStats struct {
+	consumed  atomic.Uint64
+	committed atomic.Uint64
+	rejected  atomic.Uint64
+	retried   atomic.Uint64
+	parked    atomic.Uint64
+	failed    atomic.Uint64
+
+	sinkRetried atomic.Uint64
+}
+
+// Stats возвращает текущие значения счётчиков консьюмера.
+func (c *Consumer) Stats() ConsumerStats {
+	rs := c.reader.Stats()
+	return ConsumerStats{
+		Consumed:    c.stats.consumed.Load(),
+		Committed:   c.stats.committed.Load(),
+		Rejected:    c.stats.rejected.Load(),
+		Retried:     c.stats.retried.Load(),
+		Parked:      c.stats.parked.Load(),
+		Failed:      c.stats.failed.Load(),
+		SinkRetried: c.stats.sinkRetried.Load(),
+		Lag:         rs.Lag,
+		Offset:      rs.Offset,
+		QueueLength: rs.QueueLength,
+	}
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// FailedOrder — сообщение, которое не удалось обработать после всех повторов.
+// Сохраняется в failed_orders, если dead-letter топик выключен.
+type FailedOrder struct {
+	OrderUID  string
+	Topic     string
+	Partition int
+	Offset    int64
+	Payload   []byte
+	Reason    string
+	Error     string
+	Attempts  int
+	RequestID string
+	FailedAt  time.Time
+}
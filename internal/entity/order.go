@@ -2,7 +2,6 @@ package entity
 
 import (
 	"errors"
-	"fmt"
 	"time"
 )
 
@@ -34,6 +33,9 @@ type OrderInfo struct {
 	SmID              int          `json:"sm_id"`
 	DateCreated       time.Time    `json:"date_created"`
 	OofShard          string       `json:"oof_shard"`
+
+	// заполняется из бд, во входящих заказах не передаётся
+	Status OrderStatus `json:"-"`
 }
 
 // validated
@@ -73,95 +75,21 @@ type ItemInfo struct {
 	NmID        int64  `json:"nm_id"`
 	Brand       string `json:"brand"`
 	Status      int64  `json:"status"`
+
+	// этап жизненного цикла позиции, заполняется из бд
+	State OrderStatus `json:"-"`
 }
 
 type RequestIDKey struct{}
 
+// ValidateOrder проверяет заказ набором правил DefaultOrderRules и возвращает
+// все найденные нарушения разом в виде *ValidationError.
 func (o *OrderInfo) ValidateOrder() error {
 	if o == nil {
-		return errors.New("nil order")
-	}
-
-	if o.OrderUID == "" {
-		return errors.New("empty order_uid")
-	}
-	if o.TrackNumber == "" {
-		return errors.New("empty track_number")
-	}
-	if o.Entry == "" {
-		return errors.New("empty entry")
-	}
-	if o.Locale == "" {
-		return errors.New("empty locale")
-	}
-	if o.CustomerID == "" {
-		return errors.New("empty customer_id")
-	}
-	if o.DeliveryService == "" {
-		return errors.New("empty delivery_service")
-	}
-	if o.DateCreated.IsZero() {
-		return errors.New("empty date_created")
-	}
-
-	// Delivery
-	if o.Delivery.Name == "" {
-		return errors.New("empty delivery.name")
-	}
-	if o.Delivery.Phone == "" {
-		return errors.New("empty delivery.phone")
+		return &ValidationError{Violations: Violations{{Code: CodeRequired, Message: "order is empty"}}}
 	}
-	if o.Delivery.City == "" {
-		return errors.New("empty delivery.city")
+	if v := DefaultOrderRules.Validate(o); len(v) > 0 {
+		return &ValidationError{Violations: v}
 	}
-	if o.Delivery.Address == "" {
-		return errors.New("empty delivery.address")
-	}
-	if o.Delivery.Email == "" {
-		return errors.New("empty delivery.email")
-	}
-
-	// Payment
-	if o.Payment.Transaction == "" {
-		return errors.New("empty payment.transaction")
-	}
-	if o.Payment.Currency == "" {
-		return errors.New("empty payment.currency")
-	}
-	if o.Payment.Provider == "" {
-		return errors.New("empty payment.provider")
-	}
-	if o.Payment.Amount <= 0 {
-		return errors.New("invalid payment.amount")
-	}
-	if o.Payment.PaymentDT <= 0 {
-		return errors.New("invalid payment.payment_dt")
-	}
-	if o.Payment.Bank == "" {
-		return errors.New("empty payment.bank")
-	}
-
-	// Items
-	if len(o.Items) == 0 {
-		return errors.New("empty items")
-	}
-	for i, it := range o.Items {
-		if it.ChrtID == 0 {
-			return fmt.Errorf("item[%d]: empty chrt_id", i)
-		}
-		if it.TrackNumber == "" {
-			return fmt.Errorf("item[%d]: empty track_number", i)
-		}
-		if it.Name == "" {
-			return fmt.Errorf("item[%d]: empty name", i)
-		}
-		if it.Price <= 0 {
-			return fmt.Errorf("item[%d]: invalid price", i)
-		}
-		if it.TotalPrice <= 0 {
-			return fmt.Errorf("item[%d]: invalid total_price", i)
-		}
-	}
-
 	return nil
 }
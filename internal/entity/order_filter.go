@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OrderFilter — фильтры поиска заказов. Пустые поля не участвуют в запросе.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	DateFrom        time.Time
+	DateTo          time.Time
+	PaymentProvider string
+	PaymentBank     string
+	ItemNmID        int64
+	ItemBrand       string
+
+	Limit int
+	After *OrderCursor
+}
+
+// OrderCursor — позиция keyset-пагинации: последний заказ предыдущей страницы
+// в порядке (created_at DESC, order_uid DESC).
+type OrderCursor struct {
+	CreatedAt time.Time `json:"c"`
+	OrderUID  string    `json:"u"`
+}
+
+// Encode возвращает непрозрачную для клиента строку курсора.
+func (c OrderCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeOrderCursor разбирает курсор, полученный от клиента.
+func DecodeOrderCursor(s string) (*OrderCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &OrderCursor{}
+	if err := json.Unmarshal(b, c); err != nil || c.OrderUID == "" || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// OrderPage — страница результатов поиска из репозитория.
+type OrderPage struct {
+	Orders []*OrderInfo
+	Next   *OrderCursor
+}
+
+// OrderListResponse — страница заказов для API.
+type OrderListResponse struct {
+	Orders     []*OrderResponse `json:"orders"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// OrderRefs — результат поиска по вторичному ключу (track_number, transaction,
+// customer_id), хранимый во вторичном кэше: только order_uid, сами заказы
+// берутся из основного кэша.
+type OrderRefs struct {
+	UIDs       []string
+	NextCursor string
+}
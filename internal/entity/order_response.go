@@ -8,6 +8,7 @@ type OrderResponse struct {
 	OrderUID    string         `json:"order_uid"`
 	DateCreated time.Time      `json:"date_created"`
 	Locale      string         `json:"locale"`
+	Status      OrderStatus    `json:"status"`
 	Logistics   LogisticsInfo  `json:"logistics"`
 	Delivery    DeliveryPublic `json:"delivery"`
 	Payment     PaymentPublic  `json:"payment"`
@@ -36,10 +37,33 @@ type PaymentPublic struct {
 }
 
 type ItemPublic struct {
-	Name       string `json:"name"`
-	Brand      string `json:"brand"`
-	Size       string `json:"size"`
-	Price      int64  `json:"price"`
-	TotalPrice int64  `json:"total_price"`
-	Status     int64  `json:"status"`
+	ChrtID     int64       `json:"chrt_id"`
+	Name       string      `json:"name"`
+	Brand      string      `json:"brand"`
+	Size       string      `json:"size"`
+	Price      int64       `json:"price"`
+	TotalPrice int64       `json:"total_price"`
+	Status     int64       `json:"status"`
+	State      OrderStatus `json:"state"`
+}
+
+// ApproxSize — примерный объём ответа в памяти в байтах: длины строк плюс
+// фиксированные накладные расходы на структуры. Используется как стоимость записи в кэше.
+func (o *OrderResponse) ApproxSize() int64 {
+	if o == nil {
+		return 0
+	}
+	const (
+		orderOverhead = 256
+		itemOverhead  = 96
+	)
+	size := int64(orderOverhead + len(o.OrderUID) + len(o.Locale) + len(o.Status) +
+		len(o.Logistics.TrackNumber) + len(o.Logistics.DeliveryService) +
+		len(o.Delivery.Name) + len(o.Delivery.City) + len(o.Delivery.Region) +
+		len(o.Delivery.Address) + len(o.Delivery.Email) + len(o.Delivery.Phone) +
+		len(o.Payment.Currency))
+	for _, it := range o.Items {
+		size += int64(itemOverhead + len(it.Name) + len(it.Brand) + len(it.Size) + len(it.State))
+	}
+	return size
 }
@@ -0,0 +1,18 @@
+package entity
+
+// OrderOutcome — итог пакетной вставки одного заказа.
+type OrderOutcome string
+
+const (
+	OutcomeInserted      OrderOutcome = "inserted"
+	OutcomeAlreadyExists OrderOutcome = "already_exists"
+	OutcomeInvalid       OrderOutcome = "invalid"
+	OutcomeFailed        OrderOutcome = "failed"
+)
+
+// OrderResult — результат обработки одного заказа из пакета.
+type OrderResult struct {
+	OrderUID string
+	Outcome  OrderOutcome
+	Err      error
+}
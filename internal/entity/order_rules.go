@@ -0,0 +1,284 @@
+package entity
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// коды нарушений
+const (
+	CodeRequired     = "required"
+	CodePositive     = "must_be_positive"
+	CodeOutOfRange   = "out_of_range"
+	CodeMismatch     = "mismatch"
+	CodeFormat       = "invalid_format"
+	CodeUnsupported  = "unsupported_value"
+	CodeInvalidType  = "invalid_type"
+	CodeUnknownField = "unknown_field"
+	CodeDuplicate    = "duplicate"
+)
+
+// Violation — нарушение одного правила; Field — путь к полю в JSON-модели
+// заказа, например payment.goods_total или items[0].total_price.
+type Violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Violations — все нарушения, найденные в заказе.
+type Violations []Violation
+
+func (v Violations) String() string {
+	parts := make([]string, len(v))
+	for i, vi := range v {
+		if vi.Field == "" {
+			parts[i] = vi.Message
+			continue
+		}
+		parts[i] = vi.Field + ": " + vi.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ValidationError — заказ не прошёл валидацию. Несёт все нарушения, чтобы
+// контроллеры могли показать клиенту, какие поля неверны; errors.Is(err, ErrInvalidInput) == true.
+type ValidationError struct {
+	Violations Violations
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid order: " + e.Violations.String()
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// OrderRule — одно правило проверки заказа. Правило не прерывает проверку,
+// а дописывает все найденные нарушения.
+type OrderRule struct {
+	Name  string
+	Check func(o *OrderInfo, add func(field, code, message string))
+}
+
+// OrderRules — набор правил, применяемых к заказу по порядку.
+type OrderRules []OrderRule
+
+// Validate прогоняет заказ через все правила и возвращает собранные нарушения.
+func (rs OrderRules) Validate(o *OrderInfo) Violations {
+	var out Violations
+	add := func(field, code, message string) {
+		out = append(out, Violation{Field: field, Code: code, Message: message})
+	}
+	for _, r := range rs {
+		r.Check(o, add)
+	}
+	return out
+}
+
+// DefaultOrderRules — правила, которым должен соответствовать входящий заказ.
+var DefaultOrderRules = OrderRules{
+	{Name: "required_fields", Check: checkRequired},
+	{Name: "item_total_price", Check: checkItemTotals},
+	{Name: "item_track_number", Check: checkItemTrackNumbers},
+	{Name: "goods_total", Check: checkGoodsTotal},
+	{Name: "payment_amount", Check: checkAmount},
+	{Name: "currency", Check: checkCurrency},
+	{Name: "contacts", Check: checkContacts},
+	{Name: "locale", Check: checkLocale},
+}
+
+// AllowedLocales — локали, которые поддерживает витрина.
+var AllowedLocales = map[string]struct{}{
+	"ru": {}, "en": {}, "kk": {}, "be": {}, "hy": {}, "ky": {}, "uz": {},
+}
+
+var (
+	// E.164: плюс, код страны и до 15 цифр всего
+	phoneRe = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	zipRe   = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z -]{1,9}$`)
+)
+
+func itemField(i int, name string) string {
+	return fmt.Sprintf("items[%d].%s", i, name)
+}
+
+func checkRequired(o *OrderInfo, add func(field, code, message string)) {
+	required := []struct {
+		field string
+		empty bool
+	}{
+		{"order_uid", o.OrderUID == ""},
+		{"track_number", o.TrackNumber == ""},
+		{"entry", o.Entry == ""},
+		{"locale", o.Locale == ""},
+		{"customer_id", o.CustomerID == ""},
+		{"delivery_service", o.DeliveryService == ""},
+		{"date_created", o.DateCreated.IsZero()},
+		{"delivery.name", o.Delivery.Name == ""},
+		{"delivery.phone", o.Delivery.Phone == ""},
+		{"delivery.city", o.Delivery.City == ""},
+		{"delivery.address", o.Delivery.Address == ""},
+		{"delivery.email", o.Delivery.Email == ""},
+		{"payment.transaction", o.Payment.Transaction == ""},
+		{"payment.currency", o.Payment.Currency == ""},
+		{"payment.provider", o.Payment.Provider == ""},
+		{"payment.bank", o.Payment.Bank == ""},
+		{"items", len(o.Items) == 0},
+	}
+	for _, r := range required {
+		if r.empty {
+			add(r.field, CodeRequired, "must not be empty")
+		}
+	}
+
+	if o.Payment.Amount <= 0 {
+		add("payment.amount", CodePositive, "must be positive")
+	}
+	if o.Payment.PaymentDT <= 0 {
+		add("payment.payment_dt", CodePositive, "must be positive")
+	}
+	if o.Payment.DeliveryCost < 0 {
+		add("payment.delivery_cost", CodeOutOfRange, "must not be negative")
+	}
+	if o.Payment.CustomFee < 0 {
+		add("payment.custom_fee", CodeOutOfRange, "must not be negative")
+	}
+
+	// chrt_id адресует позицию при смене статуса, поэтому в заказе он уникален
+	seen := make(map[int64]int, len(o.Items))
+	for i, it := range o.Items {
+		if it.ChrtID == 0 {
+			add(itemField(i, "chrt_id"), CodeRequired, "must not be empty")
+		} else if first, ok := seen[it.ChrtID]; ok {
+			add(itemField(i, "chrt_id"), CodeDuplicate, fmt.Sprintf("duplicates items[%d].chrt_id", first))
+		} else {
+			seen[it.ChrtID] = i
+		}
+		if it.TrackNumber == "" {
+			add(itemField(i, "track_number"), CodeRequired, "must not be empty")
+		}
+		if it.Name == "" {
+			add(itemField(i, "name"), CodeRequired, "must not be empty")
+		}
+		if it.Price <= 0 {
+			add(itemField(i, "price"), CodePositive, "must be positive")
+		}
+		if it.TotalPrice <= 0 {
+			add(itemField(i, "total_price"), CodePositive, "must be positive")
+		}
+		if it.Sale < 0 || it.Sale > 100 {
+			add(itemField(i, "sale"), CodeOutOfRange, "must be between 0 and 100")
+		}
+	}
+}
+
+// checkItemTotals: total_price = price со скидкой sale процентов,
+// округление до целого в любую сторону допускается.
+func checkItemTotals(o *OrderInfo, add func(field, code, message string)) {
+	for i, it := range o.Items {
+		if it.Price <= 0 || it.Sale < 0 || it.Sale > 100 {
+			continue
+		}
+		exact := it.Price * (100 - it.Sale) // в сотых долях
+		if diff := it.TotalPrice*100 - exact; diff <= -100 || diff >= 100 {
+			add(itemField(i, "total_price"), CodeMismatch,
+				fmt.Sprintf("must equal price minus %d%% sale (%d.%02d), got %d",
+					it.Sale, exact/100, exact%100, it.TotalPrice))
+		}
+	}
+}
+
+func checkItemTrackNumbers(o *OrderInfo, add func(field, code, message string)) {
+	if o.TrackNumber == "" {
+		return
+	}
+	for i, it := range o.Items {
+		if it.TrackNumber != "" && it.TrackNumber != o.TrackNumber {
+			add(itemField(i, "track_number"), CodeMismatch,
+				fmt.Sprintf("must equal order track_number %q, got %q", o.TrackNumber, it.TrackNumber))
+		}
+	}
+}
+
+func checkGoodsTotal(o *OrderInfo, add func(field, code, message string)) {
+	if len(o.Items) == 0 {
+		return
+	}
+	var sum int64
+	for _, it := range o.Items {
+		sum += it.TotalPrice
+	}
+	if o.Payment.GoodsTotal != sum {
+		add("payment.goods_total", CodeMismatch,
+			fmt.Sprintf("must equal sum of items total_price (%d), got %d", sum, o.Payment.GoodsTotal))
+	}
+}
+
+func checkAmount(o *OrderInfo, add func(field, code, message string)) {
+	p := o.Payment
+	if p.Amount <= 0 {
+		return
+	}
+	if want := p.GoodsTotal + p.DeliveryCost + p.CustomFee; p.Amount != want {
+		add("payment.amount", CodeMismatch,
+			fmt.Sprintf("must equal goods_total + delivery_cost + custom_fee (%d), got %d", want, p.Amount))
+	}
+}
+
+func checkCurrency(o *OrderInfo, add func(field, code, message string)) {
+	if o.Payment.Currency == "" {
+		return
+	}
+	if _, ok := iso4217[o.Payment.Currency]; !ok {
+		add("payment.currency", CodeUnsupported,
+			fmt.Sprintf("must be an ISO 4217 currency code, got %q", o.Payment.Currency))
+	}
+}
+
+func checkContacts(o *OrderInfo, add func(field, code, message string)) {
+	d := o.Delivery
+	if d.Email != "" {
+		if addr, err := mail.ParseAddress(d.Email); err != nil || addr.Address != d.Email {
+			add("delivery.email", CodeFormat, "must be a valid email address")
+		}
+	}
+	if d.Phone != "" && !phoneRe.MatchString(d.Phone) {
+		add("delivery.phone", CodeFormat, "must be in E.164 format, e.g. +79001234567")
+	}
+	if d.Zip != "" && !zipRe.MatchString(d.Zip) {
+		add("delivery.zip", CodeFormat, "must be 2-10 letters, digits, spaces or dashes")
+	}
+}
+
+func checkLocale(o *OrderInfo, add func(field, code, message string)) {
+	if o.Locale == "" {
+		return
+	}
+	if _, ok := AllowedLocales[o.Locale]; !ok {
+		add("locale", CodeUnsupported, fmt.Sprintf("locale %q is not supported", o.Locale))
+	}
+}
+
+// iso4217 — действующие коды валют ISO 4217.
+var iso4217 = func() map[string]struct{} {
+	codes := strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV
+		BRL BSD BTN BWP BYN BZD CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUP CVE CZK
+		DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL
+		HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT
+		LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR
+		MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF
+		SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP
+		TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF XAG XAU
+		XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF XPT XSU XTS XUA XXX YER ZAR ZMW ZWG
+	`)
+	m := make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		m[c] = struct{}{}
+	}
+	return m
+}()
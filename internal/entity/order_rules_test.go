@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func validOrder() *OrderInfo {
+	return &OrderInfo{
+		OrderUID:        "b563feb7b2b84b6test",
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		ShardKey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+		Delivery: DeliveryInfo{
+			Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+			Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+		},
+		Payment: PaymentInfo{
+			Transaction: "b563feb7b2b84b6test", Currency: "USD", Provider: "wbpay",
+			Amount: 2317, PaymentDT: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 817, CustomFee: 0,
+		},
+		Items: []ItemInfo{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Sale: 30, TotalPrice: 317, Name: "Mascaras"},
+			// 333 * 0.85 = 283.05, округление вверх тоже допустимо
+			{ChrtID: 9934931, TrackNumber: "WBILMTESTTRACK", Price: 333, Sale: 15, TotalPrice: 284, Name: "Lipstick"},
+			{ChrtID: 9934932, TrackNumber: "WBILMTESTTRACK", Price: 216, Sale: 0, TotalPrice: 216, Name: "Brush"},
+		},
+	}
+}
+
+func violations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.ErrorIs(t, err, ErrInvalidInput)
+	return fields(verr.Violations)
+}
+
+func fields(v Violations) map[string]string {
+	out := make(map[string]string, len(v))
+	for _, vi := range v {
+		out[vi.Field] = vi.Code
+	}
+	return out
+}
+
+func TestValidateOrderAcceptsConsistentOrder(t *testing.T) {
+	require.NoError(t, validOrder().ValidateOrder())
+}
+
+func TestValidateOrderReportsAllViolations(t *testing.T) {
+	o := validOrder()
+	o.Locale = "fr"
+	o.Payment.Currency = "RUR"
+	o.Payment.Amount = 100
+	o.Delivery.Email = "not-an-email"
+	o.Delivery.Phone = "8 (900) 123"
+	o.Delivery.Zip = "#"
+	o.Items[1].TotalPrice = 300
+	o.Items[2].TrackNumber = "OTHER"
+	o.Items[2].Name = ""
+
+	err := o.ValidateOrder()
+	require.Equal(t, map[string]string{
+		"locale":                CodeUnsupported,
+		"payment.currency":      CodeUnsupported,
+		"payment.amount":        CodeMismatch,
+		"payment.goods_total":   CodeMismatch,
+		"delivery.email":        CodeFormat,
+		"delivery.phone":        CodeFormat,
+		"delivery.zip":          CodeFormat,
+		"items[1].total_price":  CodeMismatch,
+		"items[2].track_number": CodeMismatch,
+		"items[2].name":         CodeRequired,
+	}, violations(t, err))
+	require.Contains(t, err.Error(), "payment.goods_total: must equal sum of items total_price (833), got 817")
+}
+
+func TestValidateOrderRequiredFields(t *testing.T) {
+	got := violations(t, (&OrderInfo{}).ValidateOrder())
+	for _, field := range []string{"order_uid", "track_number", "date_created", "delivery.email", "payment.currency", "items"} {
+		require.Equal(t, CodeRequired, got[field], field)
+	}
+	require.Equal(t, CodePositive, got["payment.amount"])
+	require.NotContains(t, got, "payment.goods_total", "sum rules skip orders without items")
+}
+
+func TestValidateOrderItemSaleRange(t *testing.T) {
+	o := validOrder()
+	o.Items[0].Sale = 120
+
+	require.Equal(t, map[string]string{"items[0].sale": CodeOutOfRange}, violations(t, o.ValidateOrder()))
+}
+
+func TestValidateOrderItemDuplicateChrtID(t *testing.T) {
+	o := validOrder()
+	o.Items[2].ChrtID = o.Items[0].ChrtID
+
+	require.Equal(t, map[string]string{"items[2].chrt_id": CodeDuplicate}, violations(t, o.ValidateOrder()))
+}
+
+func TestOrderRulesCustomSet(t *testing.T) {
+	rules := OrderRules{{Name: "no_test_customers", Check: func(o *OrderInfo, add func(field, code, message string)) {
+		if o.CustomerID == "test" {
+			add("customer_id", CodeUnsupported, "test customers are not allowed")
+		}
+	}}}
+
+	require.Equal(t, Violations{{Field: "customer_id", Code: CodeUnsupported, Message: "test customers are not allowed"}},
+		rules.Validate(validOrder()))
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrStatusConflict    = errors.New("status was changed concurrently")
+)
+
+// OrderStatus — этап жизненного цикла заказа или отдельной позиции.
+type OrderStatus string
+
+const (
+	StatusCreated   OrderStatus = "created"
+	StatusPaid      OrderStatus = "paid"
+	StatusAssembled OrderStatus = "assembled"
+	StatusShipped   OrderStatus = "shipped"
+	StatusDelivered OrderStatus = "delivered"
+	StatusCancelled OrderStatus = "cancelled"
+	StatusReturned  OrderStatus = "returned"
+)
+
+// statusTransitions — разрешённые переходы. cancelled и returned терминальные.
+var statusTransitions = map[OrderStatus][]OrderStatus{
+	StatusCreated:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusAssembled, StatusCancelled},
+	StatusAssembled: {StatusShipped, StatusCancelled},
+	StatusShipped:   {StatusDelivered, StatusReturned},
+	StatusDelivered: {StatusReturned},
+	StatusCancelled: {},
+	StatusReturned:  {},
+}
+
+// Valid сообщает, известен ли статус.
+func (s OrderStatus) Valid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo сообщает, разрешён ли переход из s в next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusUpdate — запрос на смену статуса заказа (ChrtID == 0) или его позиции.
+type StatusUpdate struct {
+	OrderUID string      `json:"order_uid"`
+	ChrtID   int64       `json:"chrt_id,omitempty"`
+	Status   OrderStatus `json:"status"`
+	Reason   string      `json:"reason,omitempty"`
+}
+
+// StatusChange — применённый переход, запись order_status_history.
+type StatusChange struct {
+	OrderUID  string      `json:"order_uid"`
+	ChrtID    int64       `json:"chrt_id,omitempty"`
+	From      OrderStatus `json:"from"`
+	To        OrderStatus `json:"to"`
+	Reason    string      `json:"reason,omitempty"`
+	Source    string      `json:"source"`
+	RequestID string      `json:"request_id,omitempty"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+// источники изменения статуса
+const (
+	StatusSourceHTTP  = "http"
+	StatusSourceKafka = "kafka"
+)
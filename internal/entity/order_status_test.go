@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderStatusTransitions(t *testing.T) {
+	require.True(t, StatusCreated.CanTransitionTo(StatusPaid))
+	require.True(t, StatusShipped.CanTransitionTo(StatusReturned))
+	require.False(t, StatusCreated.CanTransitionTo(StatusShipped), "steps cannot be skipped")
+	require.False(t, StatusDelivered.CanTransitionTo(StatusCancelled))
+	require.False(t, StatusCancelled.CanTransitionTo(StatusPaid), "cancelled is terminal")
+	require.False(t, OrderStatus("lost").Valid())
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// типы событий, публикуемых через outbox
+const (
+	EventOrderCreated = "order.created"
+)
+
+// OutboxEvent — событие, записанное в outbox в одной транзакции с изменением данных
+// и ожидающее публикации.
+type OutboxEvent struct {
+	ID          int64
+	EventType   string
+	AggregateID string
+	Payload     []byte
+	RequestID   string
+	CreatedAt   time.Time
+	Attempts    int
+}
@@ -0,0 +1,115 @@
+// Package health собирает проверки зависимостей сервиса для readiness-пробы.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// CheckFunc проверяет одну зависимость; nil означает, что она доступна.
+type CheckFunc func(ctx context.Context) error
+
+type namedCheck struct {
+	name string
+	fn   CheckFunc
+}
+
+// ComponentStatus — результат проверки одной зависимости.
+type ComponentStatus struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// Report — сводка readiness по всем зависимостям.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Ready сообщает, готов ли сервис принимать трафик.
+func (r Report) Ready() bool {
+	return r.Status == StatusUp
+}
+
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []namedCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// New создаёт Checker; timeout ограничивает каждую проверку отдельно.
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register добавляет проверку зависимости под именем name.
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, fn: fn})
+}
+
+// SetShuttingDown переводит readiness в failing, чтобы балансировщик успел снять трафик.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready запускает все проверки параллельно и собирает отчёт.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]ComponentStatus, len(checks)+1),
+	}
+	if c.shuttingDown.Load() {
+		report.Status = StatusDown
+		report.Components["shutdown"] = ComponentStatus{Status: StatusDown, Error: ErrShuttingDown.Error()}
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctxChk, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			t1 := time.Now()
+			err := chk.fn(ctxChk)
+			st := ComponentStatus{Status: StatusUp, LatencyMs: time.Since(t1).Milliseconds()}
+			if err != nil {
+				st.Status = StatusDown
+				st.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Components[chk.name] = st
+			if err != nil {
+				report.Status = StatusDown
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
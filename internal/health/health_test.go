@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadyAggregatesComponents(t *testing.T) {
+	t.Parallel()
+
+	c := New(50 * time.Millisecond)
+	c.Register("ok", func(context.Context) error { return nil })
+	c.Register("broken", func(context.Context) error { return errors.New("connection refused") })
+	c.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	r := c.Ready(context.Background())
+	require.False(t, r.Ready())
+	require.Equal(t, StatusUp, r.Components["ok"].Status)
+	require.Equal(t, StatusDown, r.Components["broken"].Status)
+	require.Equal(t, "connection refused", r.Components["broken"].Error)
+	require.Equal(t, StatusDown, r.Components["slow"].Status, "check is bounded by timeout")
+}
+
+func TestShuttingDownFailsReadiness(t *testing.T) {
+	t.Parallel()
+
+	c := New(time.Second)
+	c.Register("ok", func(context.Context) error { return nil })
+	require.True(t, c.Ready(context.Background()).Ready())
+
+	c.SetShuttingDown()
+	r := c.Ready(context.Background())
+	require.False(t, r.Ready())
+	require.Equal(t, StatusDown, r.Components["shutdown"].Status)
+}
@@ -0,0 +1,20 @@
+package prom_metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// CacheRemovals считает удаления из кэша по причине (capacity, deleted, purged).
+// Пополняется из колбэка OnEvict кэша.
+var CacheRemovals = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "cache_removals_total",
+	Help: "Entries removed from the cache by reason.",
+}, []string{"cache", "reason"})
+
+// CacheSharedRequests считает обращения к общему (сетевому) уровню кэша
+// по операции и результату: hit, miss, ok, error, skipped (уровень временно отключён).
+var CacheSharedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "cache_shared_requests_total",
+	Help: "Requests to the shared cache tier by operation and result.",
+}, []string{"op", "result"})
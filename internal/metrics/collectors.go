@@ -0,0 +1,180 @@
+package prom_metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// KafkaStats — снимок состояния консьюмера для KafkaCollector.
+type KafkaStats struct {
+	Consumed    uint64
+	Committed   uint64
+	Rejected    uint64
+	Retried     uint64
+	Parked      uint64
+	Failed      uint64
+	SinkRetried uint64
+	Lag         int64
+	Offset      int64
+	QueueLength int64
+}
+
+type kafkaCollector struct {
+	stats func() KafkaStats
+
+	consumed, committed, rejected, retried, parked, failed, sinkRetried *prometheus.Desc
+	lag, offset, queueLength                                            *prometheus.Desc
+}
+
+// NewKafkaCollector снимает счётчики консьюмера и lag из kafka.Reader.Stats() на каждый scrape.
+func NewKafkaCollector(topic string, stats func() KafkaStats) prometheus.Collector {
+	labels := prometheus.Labels{"topic": topic}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("kafka_consumer_"+name, help, nil, labels)
+	}
+	return &kafkaCollector{
+		stats:       stats,
+		consumed:    desc("messages_consumed_total", "Messages fetched from Kafka."),
+		committed:   desc("messages_committed_total", "Offsets committed to Kafka."),
+		rejected:    desc("messages_rejected_total", "Messages rejected as malformed or invalid."),
+		retried:     desc("handler_retries_total", "Handler retries after internal errors."),
+		parked:      desc("messages_parked_total", "Messages parked after exhausting retries."),
+		failed:      desc("messages_failed_total", "Messages whose handler failed after all retries."),
+		sinkRetried: desc("sink_retries_total", "Dead-letter publish and parking retries while the sink is unavailable."),
+		lag:         desc("lag", "Consumer lag reported by the reader."),
+		offset:      desc("offset", "Current reader offset."),
+		queueLength: desc("queue_length", "Messages buffered in the reader queue."),
+	}
+}
+
+func (c *kafkaCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.consumed, c.committed, c.rejected, c.retried, c.parked, c.failed, c.sinkRetried,
+		c.lag, c.offset, c.queueLength,
+	} {
+		ch <- d
+	}
+}
+
+func (c *kafkaCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	counter := func(d *prometheus.Desc, v uint64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, float64(v))
+	}
+	gauge := func(d *prometheus.Desc, v int64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, float64(v))
+	}
+	counter(c.consumed, s.Consumed)
+	counter(c.committed, s.Committed)
+	counter(c.rejected, s.Rejected)
+	counter(c.retried, s.Retried)
+	counter(c.parked, s.Parked)
+	counter(c.failed, s.Failed)
+	counter(c.sinkRetried, s.SinkRetried)
+	gauge(c.lag, s.Lag)
+	gauge(c.offset, s.Offset)
+	gauge(c.queueLength, s.QueueLength)
+}
+
+// CacheStats — снимок счётчиков кэша для CacheCollector.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Expired   uint64
+	Size      int
+	Cost      int64
+}
+
+type cacheCollector struct {
+	stats                                        func() CacheStats
+	hits, misses, evictions, expired, size, cost *prometheus.Desc
+}
+
+// NewCacheCollector публикует попадания, промахи, вытеснения, истечения TTL, размер и стоимость кэша с именем name.
+func NewCacheCollector(name string, stats func() CacheStats) prometheus.Collector {
+	labels := prometheus.Labels{"cache": name}
+	return &cacheCollector{
+		stats:     stats,
+		hits:      prometheus.NewDesc("cache_hits_total", "Cache lookups that found a value.", nil, labels),
+		misses:    prometheus.NewDesc("cache_misses_total", "Cache lookups that found nothing.", nil, labels),
+		evictions: prometheus.NewDesc("cache_evictions_total", "Entries evicted from the cache.", nil, labels),
+		expired:   prometheus.NewDesc("cache_expired_total", "Entries removed after their TTL elapsed.", nil, labels),
+		size:      prometheus.NewDesc("cache_entries", "Entries currently stored in the cache.", nil, labels),
+		cost:      prometheus.NewDesc("cache_cost", "Total cost of stored entries (approximate bytes for orders).", nil, labels),
+	}
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.expired
+	ch <- c.size
+	ch <- c.cost
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.expired, prometheus.CounterValue, float64(s.Expired))
+	ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(s.Size))
+	ch <- prometheus.MustNewConstMetric(c.cost, prometheus.GaugeValue, float64(s.Cost))
+}
+
+type pgxPoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired, idle, total, max, constructing *prometheus.Desc
+	acquireCount, acquireDuration            *prometheus.Desc
+	emptyAcquire, canceledAcquire            *prometheus.Desc
+}
+
+// NewPgxPoolCollector публикует pgxpool.Stat() на каждый scrape.
+func NewPgxPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("pgxpool_"+name, help, nil, nil)
+	}
+	return &pgxPoolCollector{
+		pool:            pool,
+		acquired:        desc("acquired_conns", "Connections currently acquired."),
+		idle:            desc("idle_conns", "Idle connections in the pool."),
+		total:           desc("total_conns", "Total connections in the pool."),
+		max:             desc("max_conns", "Maximum size of the pool."),
+		constructing:    desc("constructing_conns", "Connections being established."),
+		acquireCount:    desc("acquire_count_total", "Successful acquires from the pool."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquire:    desc("empty_acquire_count_total", "Acquires that waited for a connection."),
+		canceledAcquire: desc("canceled_acquire_count_total", "Acquires canceled by context."),
+	}
+}
+
+func (c *pgxPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.acquired, c.idle, c.total, c.max, c.constructing,
+		c.acquireCount, c.acquireDuration, c.emptyAcquire, c.canceledAcquire,
+	} {
+		ch <- d
+	}
+}
+
+func (c *pgxPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+	gauge(c.acquired, float64(s.AcquiredConns()))
+	gauge(c.idle, float64(s.IdleConns()))
+	gauge(c.total, float64(s.TotalConns()))
+	gauge(c.max, float64(s.MaxConns()))
+	gauge(c.constructing, float64(s.ConstructingConns()))
+	counter(c.acquireCount, float64(s.AcquireCount()))
+	counter(c.acquireDuration, s.AcquireDuration().Seconds())
+	counter(c.emptyAcquire, float64(s.EmptyAcquireCount()))
+	counter(c.canceledAcquire, float64(s.CanceledAcquireCount()))
+}
@@ -0,0 +1,19 @@
+package prom_metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// OutboxDispatches считает попытки публикации пачек outbox по результату (ok, error).
+	OutboxDispatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_dispatches_total",
+		Help: "Outbox batch publish attempts by result.",
+	}, []string{"result"})
+
+	OutboxPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_events_published_total",
+		Help: "Outbox events published downstream.",
+	})
+)
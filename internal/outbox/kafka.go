@@ -0,0 +1,69 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/segmentio/kafka-go"
+)
+
+// заголовки публикуемых событий
+const (
+	HeaderEventType = "event-type"
+	HeaderEventID   = "event-id"
+	HeaderRequestID = "request-id"
+)
+
+// Writer — часть *kafka.Writer, нужная публикатору.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaPublisher публикует события в топик; ключ сообщения — идентификатор агрегата,
+// поэтому события одного заказа попадают в одну партицию.
+type KafkaPublisher struct {
+	w Writer
+}
+
+func NewKafkaPublisher(w Writer) *KafkaPublisher {
+	return &KafkaPublisher{w: w}
+}
+
+// NewKafkaWriter создаёт writer для топика событий с подтверждением от всех реплик.
+func NewKafkaWriter(brokers []string, topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: false,
+		BatchTimeout:           50 * time.Millisecond,
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, events []entity.OutboxEvent) error {
+	msgs := make([]kafka.Message, len(events))
+	for i, ev := range events {
+		headers := []kafka.Header{
+			{Key: HeaderEventType, Value: []byte(ev.EventType)},
+			{Key: HeaderEventID, Value: []byte(strconv.FormatInt(ev.ID, 10))},
+		}
+		if ev.RequestID != "" {
+			headers = append(headers, kafka.Header{Key: HeaderRequestID, Value: []byte(ev.RequestID)})
+		}
+		msgs[i] = kafka.Message{
+			Key:     []byte(ev.AggregateID),
+			Value:   ev.Payload,
+			Headers: headers,
+			Time:    ev.CreatedAt,
+		}
+	}
+	return p.w.WriteMessages(ctx, msgs...)
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
@@ -0,0 +1,38 @@
+package outbox
+
+import "time"
+
+// Option -.
+type Option func(*Relay)
+
+// BatchSize задаёт максимальное число событий в одной публикации.
+func BatchSize(size int) Option {
+	return func(r *Relay) {
+		if size > 0 {
+			r.batchSize = size
+		}
+	}
+}
+
+// Interval задаёт паузу между выборками, когда outbox пуст.
+func Interval(interval time.Duration) Option {
+	return func(r *Relay) {
+		if interval > 0 {
+			r.interval = interval
+		}
+	}
+}
+
+// MaxBackoff ограничивает задержку после серии ошибок публикации.
+func MaxBackoff(backoff time.Duration) Option {
+	return func(r *Relay) {
+		r.maxBackoff = backoff
+	}
+}
+
+// Retention задаёт, сколько хранить отправленные события; 0 — не удалять.
+func Retention(retention time.Duration) Option {
+	return func(r *Relay) {
+		r.retention = retention
+	}
+}
@@ -0,0 +1,126 @@
+// Package outbox публикует события, записанные в таблицу outbox
+// в одной транзакции с изменением данных (transactional outbox).
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	prom_metrics "github.com/RozmiDan/wb_tech_testtask/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// Store — хранилище событий outbox.
+type Store interface {
+	DispatchOutbox(ctx context.Context, limit int, publish func(context.Context, []entity.OutboxEvent) error) (int, error)
+	PruneOutbox(ctx context.Context, sentBefore time.Time) (int64, error)
+}
+
+// Publisher доставляет пачку событий получателю. Ошибка означает,
+// что ни одно событие пачки не считается отправленным.
+type Publisher interface {
+	Publish(ctx context.Context, events []entity.OutboxEvent) error
+}
+
+// Relay периодически забирает неотправленные события из Store и публикует их.
+type Relay struct {
+	store      Store
+	pub        Publisher
+	log        *zap.Logger
+	batchSize  int
+	interval   time.Duration
+	maxBackoff time.Duration
+	retention  time.Duration
+	now        func() time.Time
+}
+
+func New(store Store, pub Publisher, logger *zap.Logger, opts ...Option) *Relay {
+	r := &Relay{
+		store:      store,
+		pub:        pub,
+		log:        logger.With(zap.String("layer", "OutboxRelay")),
+		batchSize:  100,
+		interval:   time.Second,
+		maxBackoff: 30 * time.Second,
+		retention:  24 * time.Hour,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run разбирает outbox до отмены ctx. Полная пачка означает, что в очереди
+// могут остаться события, и следующая выборка делается сразу; после ошибки
+// задержка растёт экспоненциально до maxBackoff.
+func (r *Relay) Run(ctx context.Context) {
+	r.log.Info("outbox relay started", zap.Int("batch_size", r.batchSize), zap.Duration("interval", r.interval))
+
+	var (
+		failures  int
+		lastPrune time.Time
+	)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("outbox relay stopped")
+			return
+		case <-timer.C:
+		}
+
+		n, err := r.RunOnce(ctx)
+		wait := r.interval
+		switch {
+		case ctx.Err() != nil:
+			continue
+		case err != nil:
+			failures++
+			wait = r.backoff(failures)
+			r.log.Warn("outbox dispatch failed",
+				zap.Int("failures", failures), zap.Duration("retry_in", wait), zap.Error(err))
+		case n == r.batchSize:
+			failures = 0
+			wait = 0
+		default:
+			failures = 0
+		}
+
+		if r.retention > 0 && r.now().Sub(lastPrune) >= r.retention/24 {
+			lastPrune = r.now()
+			if pruned, err := r.store.PruneOutbox(ctx, lastPrune.Add(-r.retention)); err != nil {
+				r.log.Warn("outbox prune failed", zap.Error(err))
+			} else if pruned > 0 {
+				r.log.Info("outbox pruned", zap.Int64("count", pruned))
+			}
+		}
+
+		timer.Reset(wait)
+	}
+}
+
+// RunOnce публикует одну пачку событий и возвращает их количество.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	n, err := r.store.DispatchOutbox(ctx, r.batchSize, r.pub.Publish)
+	if err != nil {
+		prom_metrics.OutboxDispatches.WithLabelValues("error").Inc()
+		return 0, err
+	}
+	if n > 0 {
+		prom_metrics.OutboxDispatches.WithLabelValues("ok").Inc()
+		prom_metrics.OutboxPublished.Add(float64(n))
+	}
+	return n, nil
+}
+
+func (r *Relay) backoff(failures int) time.Duration {
+	d := r.interval
+	for i := 1; i < failures && d < r.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.maxBackoff)
+}
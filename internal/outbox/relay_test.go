@@ -0,0 +1,199 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memStore повторяет семантику DispatchOutbox: события помечаются отправленными,
+// только если publish вернул nil.
+type memStore struct {
+	mu     sync.Mutex
+	events []entity.OutboxEvent
+	sent   map[int64]bool
+}
+
+func newMemStore(n int) *memStore {
+	s := &memStore{sent: make(map[int64]bool)}
+	for i := 1; i <= n; i++ {
+		s.events = append(s.events, entity.OutboxEvent{
+			ID: int64(i), EventType: entity.EventOrderCreated, AggregateID: "order-" + string(rune('a'+i-1)),
+		})
+	}
+	return s
+}
+
+func (s *memStore) DispatchOutbox(ctx context.Context, limit int,
+	publish func(context.Context, []entity.OutboxEvent) error) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var batch []entity.OutboxEvent
+	for i := range s.events {
+		if len(batch) == limit {
+			break
+		}
+		if !s.sent[s.events[i].ID] {
+			batch = append(batch, s.events[i])
+		}
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := publish(ctx, batch); err != nil {
+		for _, ev := range batch {
+			for i := range s.events {
+				if s.events[i].ID == ev.ID {
+					s.events[i].Attempts++
+				}
+			}
+		}
+		return 0, err
+	}
+	for _, ev := range batch {
+		s.sent[ev.ID] = true
+	}
+	return len(batch), nil
+}
+
+func (s *memStore) PruneOutbox(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func (s *memStore) unsent() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events) - len(s.sent)
+}
+
+// memSink — in-memory получатель, который может отказывать первые failN публикаций.
+type memSink struct {
+	mu        sync.Mutex
+	failN     int
+	calls     int
+	published []entity.OutboxEvent
+}
+
+func (m *memSink) Publish(_ context.Context, events []entity.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.calls <= m.failN {
+		return errors.New("sink unavailable")
+	}
+	m.published = append(m.published, events...)
+	return nil
+}
+
+func (m *memSink) ids() []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]int64, len(m.published))
+	for i, ev := range m.published {
+		ids[i] = ev.ID
+	}
+	return ids
+}
+
+func TestRelayRunOncePublishesBatchInOrder(t *testing.T) {
+	store := newMemStore(5)
+	sink := &memSink{}
+	r := New(store, sink, zap.NewNop(), BatchSize(3))
+
+	n, err := r.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	n, err = r.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	n, err = r.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.Equal(t, []int64{1, 2, 3, 4, 5}, sink.ids())
+}
+
+func TestRelayRunOnceKeepsEventsOnPublishError(t *testing.T) {
+	store := newMemStore(2)
+	sink := &memSink{failN: 1}
+	r := New(store, sink, zap.NewNop())
+
+	_, err := r.RunOnce(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 2, store.unsent())
+	require.Equal(t, 1, store.events[0].Attempts)
+
+	n, err := r.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Zero(t, store.unsent())
+}
+
+func TestRelayRunDrainsAfterFailures(t *testing.T) {
+	store := newMemStore(7)
+	sink := &memSink{failN: 2}
+	r := New(store, sink, zap.NewNop(),
+		BatchSize(2), Interval(time.Millisecond), MaxBackoff(5*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return store.unsent() == 0 }, 2*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7}, sink.ids())
+}
+
+func TestRelayBackoff(t *testing.T) {
+	r := New(newMemStore(0), &memSink{}, zap.NewNop(), Interval(time.Second), MaxBackoff(5*time.Second))
+
+	require.Equal(t, time.Second, r.backoff(1))
+	require.Equal(t, 2*time.Second, r.backoff(2))
+	require.Equal(t, 4*time.Second, r.backoff(3))
+	require.Equal(t, 5*time.Second, r.backoff(10))
+}
+
+type fakeWriter struct {
+	msgs []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+func TestKafkaPublisherMessages(t *testing.T) {
+	w := &fakeWriter{}
+	p := NewKafkaPublisher(w)
+
+	err := p.Publish(context.Background(), []entity.OutboxEvent{
+		{ID: 42, EventType: entity.EventOrderCreated, AggregateID: "b563feb7b2b84b6test", Payload: []byte(`{}`), RequestID: "req-1"},
+		{ID: 43, EventType: entity.EventOrderCreated, AggregateID: "other", Payload: []byte(`{}`)},
+	})
+	require.NoError(t, err)
+	require.Len(t, w.msgs, 2)
+
+	msg := w.msgs[0]
+	require.Equal(t, "b563feb7b2b84b6test", string(msg.Key))
+	require.Equal(t, []kafka.Header{
+		{Key: HeaderEventType, Value: []byte(entity.EventOrderCreated)},
+		{Key: HeaderEventID, Value: []byte("42")},
+		{Key: HeaderRequestID, Value: []byte("req-1")},
+	}, msg.Headers)
+	require.Len(t, w.msgs[1].Headers, 2)
+}
@@ -0,0 +1,38 @@
+package postgre
+
+import (
+	"context"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+const insertFailedOrderQuery = `
+	INSERT INTO failed_orders (
+		order_uid, topic, partition, kafka_offset, payload,
+		reason, error, attempts, request_id, failed_at)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+`
+
+func (rr *RatingRepository) SetFailedOrder(ctx context.Context, failed *entity.FailedOrder) error {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+	logger := rr.log.With(zap.String("func", "SetFailedOrder"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	if _, err := rr.pg.Pool.Exec(ctx, insertFailedOrderQuery,
+		failed.OrderUID, failed.Topic, failed.Partition, failed.Offset, failed.Payload,
+		failed.Reason, failed.Error, failed.Attempts, failed.RequestID, failed.FailedAt,
+	); err != nil {
+		logger.Error("insert failed_orders failed", zap.Error(err))
+		return entity.ErrorInsertDB
+	}
+
+	logger.Info("failed order parked",
+		zap.String("order_uid", failed.OrderUID),
+		zap.Int("partition", failed.Partition),
+		zap.Int64("offset", failed.Offset),
+	)
+	return nil
+}
@@ -111,6 +111,19 @@ func (rr *RatingRepository) SetOrder(ctx context.Context, order *entity.OrderInf
 		}
 	}
 
+	// 5) outbox — событие уйдёт дальше только вместе с закоммиченным заказом
+	if rr.outbox {
+		payload, err := orderCreatedPayload(order)
+		if err != nil {
+			logger.Error("marshal outbox payload failed", zap.Error(err))
+			return entity.ErrorInsertDB
+		}
+		if _, err := tx.Exec(ctx, insertOutboxQuery, entity.EventOrderCreated, order.OrderUID, payload, reqID); err != nil {
+			logger.Error("insert outbox failed", zap.Error(err))
+			return entity.ErrorInsertDB
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		logger.Error("commit failed", zap.Error(err))
 		return entity.ErrorInsertDB
@@ -0,0 +1,184 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var itemColumns = []string{
+	"order_uid", "chrt_id", "track_number", "price", "rid", "name",
+	"sale", "size", "total_price", "nm_id", "brand", "status",
+}
+
+// SetOrders вставляет пачку заказов в одной транзакции: заказы, доставки и оплаты
+// отправляются через pgx.Batch, позиции — через COPY. Результаты возвращаются
+// в порядке входного среза, по одному на заказ. Если пачка не вставилась
+// (например, один заказ нарушил ограничение бд или COPY упал), транзакция
+// откатывается и заказы вставляются по одному, чтобы ошибка одного заказа
+// не помечала failed остальные.
+func (rr *RatingRepository) SetOrders(ctx context.Context, orders []*entity.OrderInfo) ([]entity.OrderResult, error) {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+	logger := rr.log.With(zap.String("func", "SetOrders"), zap.Int("batch_size", len(orders)))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	results, err := rr.setOrdersBatch(ctx, orders, reqID, logger)
+	if err == nil || errors.Is(err, entity.ErrorDBConnect) || ctx.Err() != nil || len(orders) == 1 {
+		return results, err
+	}
+
+	logger.Warn("batch insert failed, inserting orders one by one", zap.Error(err))
+	for i, o := range orders {
+		results[i] = entity.OrderResult{OrderUID: o.OrderUID, Outcome: entity.OutcomeInserted}
+		switch err := rr.SetOrder(ctx, o); {
+		case err == nil:
+		case errors.Is(err, entity.ErrorOrderExists):
+			results[i].Outcome, results[i].Err = entity.OutcomeAlreadyExists, err
+		default:
+			results[i].Outcome, results[i].Err = entity.OutcomeFailed, err
+		}
+	}
+
+	return results, nil
+}
+
+// setOrdersBatch вставляет пачку одной транзакцией; при ошибке все заказы failed.
+func (rr *RatingRepository) setOrdersBatch(ctx context.Context, orders []*entity.OrderInfo,
+	reqID string, logger *zap.Logger) ([]entity.OrderResult, error) {
+	results := make([]entity.OrderResult, len(orders))
+	for i, o := range orders {
+		results[i] = entity.OrderResult{OrderUID: o.OrderUID, Outcome: entity.OutcomeFailed}
+	}
+	if len(orders) == 0 {
+		return results, nil
+	}
+
+	failAll := func(err error) ([]entity.OrderResult, error) {
+		for i := range results {
+			results[i].Outcome = entity.OutcomeFailed
+			results[i].Err = err
+		}
+		return results, err
+	}
+
+	tx, err := rr.pg.Pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:       pgx.RepeatableRead,
+		AccessMode:     pgx.ReadWrite,
+		DeferrableMode: pgx.NotDeferrable,
+	})
+	if err != nil {
+		logger.Error("begin tx failed", zap.Error(err))
+		return failAll(entity.ErrorDBConnect)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// 1) orders — по результату вставки понимаем, какие заказы новые
+	batch := &pgx.Batch{}
+	for _, o := range orders {
+		batch.Queue(insertOrdersQuery, o.OrderUID, o.TrackNumber, o.Entry,
+			o.Locale, o.InternalSignature, o.CustomerID,
+			o.DeliveryService, o.ShardKey, o.SmID,
+			o.DateCreated, o.OofShard,
+		)
+	}
+	inserted := make([]*entity.OrderInfo, 0, len(orders))
+	br := tx.SendBatch(ctx, batch)
+	for i, o := range orders {
+		cmdTg, err := br.Exec()
+		if err != nil {
+			_ = br.Close()
+			logger.Error("batch insert orders failed", zap.String("order_uid", o.OrderUID), zap.Error(err))
+			return failAll(entity.ErrorInsertDB)
+		}
+		if cmdTg.RowsAffected() == 0 {
+			results[i].Outcome = entity.OutcomeAlreadyExists
+			results[i].Err = entity.ErrorOrderExists
+			continue
+		}
+		results[i].Outcome = entity.OutcomeInserted
+		inserted = append(inserted, o)
+	}
+	if err := br.Close(); err != nil {
+		logger.Error("batch close failed", zap.Error(err))
+		return failAll(entity.ErrorInsertDB)
+	}
+
+	if len(inserted) == 0 {
+		logger.Info("all orders already exist")
+		return results, nil
+	}
+
+	// 2) deliveries + payments
+	batch = &pgx.Batch{}
+	for _, o := range inserted {
+		batch.Queue(insertDeliveryQuery, o.OrderUID,
+			o.Delivery.Name, o.Delivery.Phone, o.Delivery.Zip,
+			o.Delivery.City, o.Delivery.Address, o.Delivery.Region,
+			o.Delivery.Email,
+		)
+		batch.Queue(insertPaymentQuery, o.OrderUID,
+			o.Payment.Transaction, o.Payment.RequestID, o.Payment.Currency,
+			o.Payment.Provider, o.Payment.Amount, o.Payment.PaymentDT,
+			o.Payment.Bank, o.Payment.DeliveryCost, o.Payment.GoodsTotal,
+			o.Payment.CustomFee,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		logger.Error("batch insert deliveries/payments failed", zap.Error(err))
+		return failAll(entity.ErrorInsertDB)
+	}
+
+	// 3) items через COPY
+	rows := make([][]any, 0, len(inserted))
+	for _, o := range inserted {
+		for _, it := range o.Items {
+			rows = append(rows, []any{
+				o.OrderUID, it.ChrtID, it.TrackNumber, it.Price, it.Rid, it.Name,
+				it.Sale, it.Size, it.TotalPrice, it.NmID, it.Brand, it.Status,
+			})
+		}
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"items"}, itemColumns, pgx.CopyFromRows(rows)); err != nil {
+		logger.Error("copy items failed", zap.Error(err))
+		return failAll(entity.ErrorInsertDB)
+	}
+
+	// 4) outbox через COPY
+	if rr.outbox {
+		var requestID any
+		if reqID != "" {
+			requestID = reqID
+		}
+		events := make([][]any, 0, len(inserted))
+		for _, o := range inserted {
+			payload, err := orderCreatedPayload(o)
+			if err != nil {
+				logger.Error("marshal outbox payload failed", zap.String("order_uid", o.OrderUID), zap.Error(err))
+				return failAll(entity.ErrorInsertDB)
+			}
+			events = append(events, []any{entity.EventOrderCreated, o.OrderUID, payload, requestID})
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"outbox"}, outboxColumns, pgx.CopyFromRows(events)); err != nil {
+			logger.Error("copy outbox failed", zap.Error(err))
+			return failAll(entity.ErrorInsertDB)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("commit failed", zap.Error(err))
+		return failAll(entity.ErrorInsertDB)
+	}
+
+	logger.Info("orders batch inserted",
+		zap.Int("inserted", len(inserted)),
+		zap.Int("already_exists", len(orders)-len(inserted)),
+		zap.Int("items", len(rows)),
+	)
+	return results, nil
+}
@@ -0,0 +1,74 @@
+package postgre
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSetOrders(t *testing.T) {
+	rr := newTestRepo(t)
+	ctx := context.Background()
+
+	suffix := time.Now().Format("150405.000000")
+	oldUID, newUID := "batch-old-"+suffix, "batch-new-"+suffix
+	t.Cleanup(func() {
+		_, _ = rr.pg.Pool.Exec(context.Background(),
+			`DELETE FROM orders WHERE order_uid = ANY($1)`, []string{oldUID, newUID})
+	})
+	require.NoError(t, rr.SetOrder(ctx, testOrder(oldUID)))
+
+	res, err := rr.SetOrders(ctx, []*entity.OrderInfo{testOrder(newUID), testOrder(oldUID)})
+	require.NoError(t, err)
+	require.Equal(t, []entity.OrderResult{
+		{OrderUID: newUID, Outcome: entity.OutcomeInserted},
+		{OrderUID: oldUID, Outcome: entity.OutcomeAlreadyExists, Err: entity.ErrorOrderExists},
+	}, res)
+
+	// позиции нового заказа записаны через COPY
+	got, err := rr.GetOrderByUID(ctx, newUID)
+	require.NoError(t, err)
+	require.Equal(t, newUID, got.Payment.Transaction)
+	require.Len(t, got.Items, 1)
+	require.Equal(t, int64(9934930), got.Items[0].ChrtID)
+}
+
+func TestSetOrdersIsolatesFailedOrder(t *testing.T) {
+	rr := newTestRepo(t)
+	ctx := context.Background()
+
+	suffix := time.Now().Format("150405.000000")
+	goodUID, badUID := "batch-good-"+suffix, "batch-bad-"+suffix
+	t.Cleanup(func() {
+		_, _ = rr.pg.Pool.Exec(context.Background(),
+			`DELETE FROM orders WHERE order_uid = ANY($1)`, []string{goodUID, badUID})
+	})
+
+	// проходит валидацию, но не помещается в items.status INTEGER: COPY пачки падает
+	bad := testOrder(badUID)
+	bad.Items[0].Status = 1 << 40
+
+	res, err := rr.SetOrders(ctx, []*entity.OrderInfo{testOrder(goodUID), bad})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, entity.OrderResult{OrderUID: goodUID, Outcome: entity.OutcomeInserted}, res[0])
+	require.Equal(t, entity.OutcomeFailed, res[1].Outcome)
+	require.ErrorIs(t, res[1].Err, entity.ErrorInsertDB)
+
+	_, err = rr.GetOrderByUID(ctx, goodUID)
+	require.NoError(t, err, "the good order is saved despite the bad one")
+	_, err = rr.GetOrderByUID(ctx, badUID)
+	require.ErrorIs(t, err, entity.ErrorOrderNotFound, "the bad order leaves no partial rows")
+}
+
+func TestSetOrdersEmpty(t *testing.T) {
+	rr := New(nil, zap.NewNop())
+
+	res, err := rr.SetOrders(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
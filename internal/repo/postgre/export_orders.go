@@ -0,0 +1,129 @@
+package postgre
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// exportFetchSize — сколько строк выборки забирается из курсора за один FETCH.
+const exportFetchSize = 500
+
+var fetchExportQuery = "FETCH FORWARD " + strconv.Itoa(exportFetchSize) + " FROM order_export"
+
+// buildExportQuery собирает выборку полных заказов по фильтру для курсора
+// экспорта. Лимит и курсор пагинации не учитываются: выгружается всё.
+func buildExportQuery(f entity.OrderFilter) (string, []any) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	sb.WriteString("SELECT" + selectOrderColumns + "FROM orders o" +
+		" LEFT JOIN deliveries d ON d.order_uid = o.order_uid" +
+		" LEFT JOIN payments p ON p.order_uid = o.order_uid" +
+		" LEFT JOIN items i ON i.order_uid = o.order_uid")
+
+	if where := filterConditions(f, arg); len(where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(where, " AND "))
+	}
+	sb.WriteString(" ORDER BY o.created_at DESC, o.order_uid DESC, i.chrt_id")
+
+	return sb.String(), args
+}
+
+// ExportOrders проходит по всем заказам под фильтр серверным курсором и отдаёт
+// их в fn по одному, не держа выборку в памяти. Курсор живёт в read-only
+// транзакции REPEATABLE READ, поэтому выгрузка — согласованный снимок.
+// Ошибка fn прерывает выгрузку и возвращается как есть.
+func (rr *RatingRepository) ExportOrders(ctx context.Context, f entity.OrderFilter, fn func(*entity.OrderInfo) error) error {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	logger := rr.log.With(zap.String("func", "ExportOrders"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	tx, err := rr.pg.Pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		logger.Error("begin tx failed", zap.Error(err))
+		return entity.ErrorQueryFailed
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query, args := buildExportQuery(f)
+	if _, err := tx.Exec(ctx, "DECLARE order_export NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		logger.Error("declare cursor failed", zap.Error(err))
+		return entity.ErrorQueryFailed
+	}
+
+	// строки одного заказа идут подряд и могут попасть в разные FETCH,
+	// поэтому заказ отдаётся, только когда начался следующий
+	var (
+		cur      *entity.OrderInfo
+		exported int
+	)
+	for {
+		rows, err := tx.Query(ctx, fetchExportQuery)
+		if err != nil {
+			logger.Error("fetch failed", zap.Error(err))
+			return entity.ErrorQueryFailed
+		}
+
+		fetched := 0
+		for rows.Next() {
+			fetched++
+			row, item, err := scanOrderRow(rows)
+			if err != nil {
+				rows.Close()
+				logger.Error("scan failed", zap.Error(err))
+				return entity.ErrorQueryFailed
+			}
+
+			if cur == nil || cur.OrderUID != row.OrderUID {
+				if cur != nil {
+					if err := fn(cur); err != nil {
+						rows.Close()
+						return err
+					}
+					exported++
+				}
+				cur = row
+			}
+			if item != nil {
+				cur.Items = append(cur.Items, *item)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			logger.Error("rows error", zap.Error(err))
+			return entity.ErrorQueryFailed
+		}
+
+		if fetched < exportFetchSize {
+			break
+		}
+	}
+
+	if cur != nil {
+		if err := fn(cur); err != nil {
+			return err
+		}
+		exported++
+	}
+
+	logger.Info("export completed", zap.Int("orders", exported))
+	return nil
+}
@@ -0,0 +1,34 @@
+package postgre
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildExportQuery(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	query, args := buildExportQuery(entity.OrderFilter{
+		PaymentProvider: "wbpay",
+		DateFrom:        from,
+		ItemNmID:        2389212,
+		// пагинация в выгрузке не участвует
+		Limit: 10,
+		After: &entity.OrderCursor{CreatedAt: from, OrderUID: "b563feb7b2b84b6test"},
+	})
+
+	query = strings.Join(strings.Fields(query), " ")
+	require.True(t, strings.HasPrefix(query, "SELECT o.order_uid, o.track_number,"), query)
+	require.Contains(t, query, "FROM orders o LEFT JOIN deliveries d ON d.order_uid = o.order_uid"+
+		" LEFT JOIN payments p ON p.order_uid = o.order_uid"+
+		" LEFT JOIN items i ON i.order_uid = o.order_uid"+
+		" WHERE p.provider = $1 AND o.date_created >= $2"+
+		" AND EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.nm_id = $3)"+
+		" ORDER BY o.created_at DESC, o.order_uid DESC, i.chrt_id")
+	require.NotContains(t, query, "LIMIT")
+	require.Equal(t, []any{"wbpay", from, int64(2389212)}, args)
+}
@@ -0,0 +1,98 @@
+package postgre
+
+import (
+	"context"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+const selectOrdersByTrackQuery = `
+	WITH found AS (
+		SELECT o.order_uid
+		FROM orders o
+		WHERE o.track_number = $1
+		ORDER BY o.created_at DESC, o.order_uid DESC
+		LIMIT $2
+	)
+	SELECT` + selectOrderColumns + `
+	FROM found f
+	JOIN orders     o ON o.order_uid = f.order_uid
+	LEFT JOIN deliveries d ON d.order_uid = o.order_uid
+	LEFT JOIN payments   p ON p.order_uid = o.order_uid
+	LEFT JOIN items      i ON i.order_uid = o.order_uid
+	ORDER BY o.created_at DESC, o.order_uid DESC, i.chrt_id;
+`
+
+const selectOrderByTransactionQuery = `
+	WITH found AS (
+		SELECT p.order_uid
+		FROM payments p
+		WHERE p.transaction = $1
+		LIMIT 1
+	)
+	SELECT` + selectOrderColumns + `
+	FROM found f
+	JOIN orders     o ON o.order_uid = f.order_uid
+	LEFT JOIN deliveries d ON d.order_uid = o.order_uid
+	LEFT JOIN payments   p ON p.order_uid = o.order_uid
+	LEFT JOIN items      i ON i.order_uid = o.order_uid
+	ORDER BY i.chrt_id;
+`
+
+// GetOrdersByTrackNumber возвращает заказы с данным track_number, новые первыми.
+func (rr *RatingRepository) GetOrdersByTrackNumber(ctx context.Context, trackNumber string, limit int) ([]*entity.OrderInfo, error) {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	logger := rr.log.With(zap.String("func", "GetOrdersByTrackNumber"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	rows, err := rr.pg.Pool.Query(ctx, selectOrdersByTrackQuery, trackNumber, limit)
+	if err != nil {
+		logger.Error("query failed", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows, 1)
+	if err != nil {
+		logger.Error("scan failed", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+
+	logger.Info("The request was completed successfully",
+		zap.String("track_number", trackNumber), zap.Int("found", len(orders)))
+	return orders, nil
+}
+
+// GetOrderByTransaction возвращает заказ по payment.transaction.
+func (rr *RatingRepository) GetOrderByTransaction(ctx context.Context, transaction string) (*entity.OrderInfo, error) {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	logger := rr.log.With(zap.String("func", "GetOrderByTransaction"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	rows, err := rr.pg.Pool.Query(ctx, selectOrderByTransactionQuery, transaction)
+	if err != nil {
+		logger.Error("query failed", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows, 1)
+	if err != nil {
+		logger.Error("scan failed", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+	if len(orders) == 0 {
+		logger.Info("order not found", zap.String("transaction", transaction))
+		return nil, entity.ErrorOrderNotFound
+	}
+
+	logger.Info("The request was completed successfully", zap.String("transaction", transaction))
+	return orders[0], nil
+}
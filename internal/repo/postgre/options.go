@@ -0,0 +1,13 @@
+package postgre
+
+// Option -.
+type Option func(*RatingRepository)
+
+// Outbox включает запись событий order.created в таблицу outbox вместе с
+// заказом. Без релея (OUTBOX_ENABLED=false) события никто не отправит и не
+// удалит, поэтому запись выключается.
+func Outbox(enabled bool) Option {
+	return func(rr *RatingRepository) {
+		rr.outbox = enabled
+	}
+}
@@ -0,0 +1,123 @@
+package postgre
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	insertOutboxQuery = `
+		INSERT INTO outbox (event_type, aggregate_id, payload, request_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+	`
+	// SKIP LOCKED позволяет нескольким репликам разбирать outbox параллельно,
+	// не публикуя одно и то же событие одновременно
+	selectOutboxQuery = `
+		SELECT id, event_type, aggregate_id, payload, COALESCE(request_id, ''), created_at, attempts
+		FROM outbox
+		WHERE sent_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+	markOutboxSentQuery = `
+		UPDATE outbox SET sent_at = now(), attempts = attempts + 1, last_error = NULL
+		WHERE id = ANY($1)
+	`
+	markOutboxFailedQuery = `
+		UPDATE outbox SET attempts = attempts + 1, last_error = $2
+		WHERE id = ANY($1)
+	`
+	pruneOutboxQuery = `DELETE FROM outbox WHERE sent_at IS NOT NULL AND sent_at < $1`
+)
+
+var outboxColumns = []string{"event_type", "aggregate_id", "payload", "request_id"}
+
+// orderCreatedPayload — тело события order.created: заказ в том же JSON, что и во входящем топике.
+func orderCreatedPayload(order *entity.OrderInfo) (string, error) {
+	payload, err := json.Marshal(order)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// DispatchOutbox блокирует до limit неотправленных событий, передаёт их в publish
+// и в той же транзакции помечает отправленными. Если publish вернул ошибку,
+// у событий увеличивается счётчик попыток, и они будут выбраны снова.
+// Падение между публикацией и коммитом приводит к повторной отправке — доставка at-least-once.
+func (rr *RatingRepository) DispatchOutbox(ctx context.Context, limit int,
+	publish func(context.Context, []entity.OutboxEvent) error) (int, error) {
+	logger := rr.log.With(zap.String("func", "DispatchOutbox"))
+
+	tx, err := rr.pg.Pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		logger.Error("begin tx failed", zap.Error(err))
+		return 0, entity.ErrorDBConnect
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, selectOutboxQuery, limit)
+	if err != nil {
+		logger.Error("select outbox failed", zap.Error(err))
+		return 0, entity.ErrorQueryFailed
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.OutboxEvent, error) {
+		var ev entity.OutboxEvent
+		err := row.Scan(&ev.ID, &ev.EventType, &ev.AggregateID, &ev.Payload,
+			&ev.RequestID, &ev.CreatedAt, &ev.Attempts)
+		return ev, err
+	})
+	if err != nil {
+		logger.Error("scan outbox failed", zap.Error(err))
+		return 0, entity.ErrorQueryFailed
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(events))
+	for i, ev := range events {
+		ids[i] = ev.ID
+	}
+
+	pubErr := publish(ctx, events)
+	if pubErr != nil {
+		if _, err := tx.Exec(ctx, markOutboxFailedQuery, ids, pubErr.Error()); err != nil {
+			logger.Error("mark outbox failed", zap.Error(err))
+			return 0, pubErr
+		}
+	} else if _, err := tx.Exec(ctx, markOutboxSentQuery, ids); err != nil {
+		logger.Error("mark outbox sent failed", zap.Error(err))
+		return 0, entity.ErrorQueryFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("commit failed", zap.Error(err))
+		return 0, entity.ErrorQueryFailed
+	}
+	if pubErr != nil {
+		return 0, pubErr
+	}
+
+	return len(events), nil
+}
+
+// PruneOutbox удаляет события, отправленные раньше sentBefore.
+func (rr *RatingRepository) PruneOutbox(ctx context.Context, sentBefore time.Time) (int64, error) {
+	cmdTg, err := rr.pg.Pool.Exec(ctx, pruneOutboxQuery, sentBefore)
+	if err != nil {
+		rr.log.Error("prune outbox failed", zap.String("func", "PruneOutbox"), zap.Error(err))
+		return 0, entity.ErrorQueryFailed
+	}
+	return cmdTg.RowsAffected(), nil
+}
@@ -0,0 +1,125 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/db"
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/RozmiDan/wb_tech_testtask/pkg/postgres"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestRepo подключается к локальному PostgreSQL из TEST_POSTGRES_URL
+// и применяет миграции; без переменной тест пропускается.
+func newTestRepo(t *testing.T) *RatingRepository {
+	t.Helper()
+
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+	pg, err := postgres.New(url, postgres.MaxPoolSize(2), postgres.ConnAttempts(1))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+	require.NoError(t, db.Up(pg.Pool))
+
+	return New(pg, zap.NewNop())
+}
+
+func testOrder(uid string) *entity.OrderInfo {
+	return &entity.OrderInfo{
+		OrderUID:        uid,
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		ShardKey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+		Delivery: entity.DeliveryInfo{
+			Name: "Test Testov", Phone: "+9720000000", Zip: "2639809",
+			City: "Kiryat Mozkin", Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+		},
+		Payment: entity.PaymentInfo{
+			Transaction: uid, Currency: "USD", Provider: "wbpay", Amount: 1817,
+			PaymentDT: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+		},
+		Items: []entity.ItemInfo{{
+			ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Rid: "ab4219087a764ae0btest",
+			Name: "Mascaras", Sale: 30, Size: "0", TotalPrice: 317, NmID: 2389212, Brand: "Vivienne Sabo", Status: 202,
+		}},
+	}
+}
+
+func TestOutboxWrittenWithOrderAndDispatched(t *testing.T) {
+	rr := newTestRepo(t)
+	ctx := context.Background()
+
+	_, err := rr.pg.Pool.Exec(ctx, `TRUNCATE outbox`)
+	require.NoError(t, err)
+
+	uid := "outbox-" + time.Now().Format("150405.000000")
+	t.Cleanup(func() {
+		_, _ = rr.pg.Pool.Exec(context.Background(), `DELETE FROM orders WHERE order_uid = $1`, uid)
+	})
+	ctx = context.WithValue(ctx, entity.RequestIDKey{}, "req-1")
+	require.NoError(t, rr.SetOrder(ctx, testOrder(uid)))
+
+	// ошибка публикации: событие остаётся в outbox с увеличенным счётчиком попыток
+	errSink := errors.New("sink unavailable")
+	_, err = rr.DispatchOutbox(ctx, 10, func(context.Context, []entity.OutboxEvent) error { return errSink })
+	require.ErrorIs(t, err, errSink)
+
+	var got []entity.OutboxEvent
+	n, err := rr.DispatchOutbox(ctx, 10, func(_ context.Context, evs []entity.OutboxEvent) error {
+		got = append(got, evs...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, entity.EventOrderCreated, got[0].EventType)
+	require.Equal(t, uid, got[0].AggregateID)
+	require.Equal(t, "req-1", got[0].RequestID)
+	require.Equal(t, 1, got[0].Attempts)
+	require.Contains(t, string(got[0].Payload), uid)
+
+	// отправленное событие повторно не выбирается
+	n, err = rr.DispatchOutbox(ctx, 10, func(context.Context, []entity.OutboxEvent) error {
+		t.Fatal("sent event dispatched again")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	pruned, err := rr.PruneOutbox(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, pruned)
+}
+
+func TestOutboxSkippedWhenDisabled(t *testing.T) {
+	rr := New(newTestRepo(t).pg, zap.NewNop(), Outbox(false))
+	ctx := context.Background()
+
+	suffix := time.Now().Format("150405.000000")
+	single, batched := "no-outbox-"+suffix, "no-outbox-batch-"+suffix
+	t.Cleanup(func() {
+		_, _ = rr.pg.Pool.Exec(context.Background(),
+			`DELETE FROM orders WHERE order_uid = ANY($1)`, []string{single, batched})
+	})
+	require.NoError(t, rr.SetOrder(ctx, testOrder(single)))
+	_, err := rr.SetOrders(ctx, []*entity.OrderInfo{testOrder(batched)})
+	require.NoError(t, err)
+
+	// без релея события никто не отправит и не удалит
+	var n int
+	require.NoError(t, rr.pg.Pool.QueryRow(ctx,
+		`SELECT count(*) FROM outbox WHERE aggregate_id = ANY($1)`, []string{single, batched}).Scan(&n))
+	require.Zero(t, n)
+}
@@ -6,13 +6,20 @@ import (
 )
 
 type RatingRepository struct {
-	pg  *postgres.Postgres
-	log *zap.Logger
+	pg     *postgres.Postgres
+	log    *zap.Logger
+	outbox bool
 }
 
-func New(pg *postgres.Postgres, logger *zap.Logger) *RatingRepository {
-	return &RatingRepository{
-		pg:  pg,
-		log: logger.With(zap.String("layer", "Repository")),
+func New(pg *postgres.Postgres, logger *zap.Logger, opts ...Option) *RatingRepository {
+	rr := &RatingRepository{
+		pg:     pg,
+		log:    logger.With(zap.String("layer", "Repository")),
+		outbox: true,
 	}
+	for _, opt := range opts {
+		opt(rr)
+	}
+
+	return rr
 }
@@ -0,0 +1,147 @@
+package postgre
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+// selectOrderColumns — колонки, которые ожидает scanOrders, в нужном порядке.
+const selectOrderColumns = `
+		o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
+		o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard, o.status,
+		d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+		p.transaction, p.request_id, p.currency, p.provider, p.amount,
+		p.payment_dt, p.bank, p.delivery_cost, p.goods_total, p.custom_fee,
+		i.chrt_id, i.track_number AS item_track, i.price, i.rid, i.name AS item_name,
+		i.sale, i.size, i.total_price, i.nm_id, i.brand, i.status, i.state
+`
+
+// scanOrders собирает заказы из строк order ⋈ delivery ⋈ payment ⋈ items,
+// сохраняя порядок, в котором заказы впервые встретились в выборке.
+func scanOrders(rows pgx.Rows, capHint int) ([]*entity.OrderInfo, error) {
+	orders := make(map[string]*entity.OrderInfo, capHint)
+	orderSeq := make([]string, 0, capHint)
+
+	for rows.Next() {
+		row, item, err := scanOrderRow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ord := orders[row.OrderUID]
+		if ord == nil {
+			ord = row
+			orders[row.OrderUID] = ord
+			orderSeq = append(orderSeq, row.OrderUID)
+		}
+		if item != nil {
+			ord.Items = append(ord.Items, *item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// сохранить порядок сортировки
+	out := make([]*entity.OrderInfo, 0, len(orderSeq))
+	for _, id := range orderSeq {
+		out = append(out, orders[id])
+	}
+	return out, nil
+}
+
+// scanOrderRow читает одну строку выборки selectOrderColumns: заказ без позиций
+// и позицию этой строки (nil, если у заказа нет позиций).
+func scanOrderRow(rows pgx.Rows) (*entity.OrderInfo, *entity.ItemInfo, error) {
+	var (
+		// order
+		ordUID, trackNumber, entry, locale, internalSig, customerID, delivSvc, shardkey, oofShard string
+		smID                                                                                      int
+		dateCreated                                                                               time.Time
+		ordStatus                                                                                 string
+		// delivery
+		dName, dPhone, dZip, dCity, dAddr, dRegion, dEmail sql.NullString
+		// payment
+		pTrans, pReqID, pCurr, pProv, pBank                 sql.NullString
+		pAmount, pDelCost, pGoodsTotal, pCustomFee, pPaidDt sql.NullInt64
+		// item
+		chrtID                                sql.NullInt64
+		itemTrack, rid, itemName, size, brand sql.NullString
+		price, sale, totalPrice, nmID, status sql.NullInt64
+		state                                 sql.NullString
+	)
+
+	if err := rows.Scan(
+		// order
+		&ordUID, &trackNumber, &entry, &locale, &internalSig,
+		&customerID, &delivSvc, &shardkey, &smID, &dateCreated, &oofShard, &ordStatus,
+		// delivery
+		&dName, &dPhone, &dZip, &dCity, &dAddr, &dRegion, &dEmail,
+		// payment
+		&pTrans, &pReqID, &pCurr, &pProv, &pAmount,
+		&pPaidDt, &pBank, &pDelCost, &pGoodsTotal, &pCustomFee,
+		// items
+		&chrtID, &itemTrack, &price, &rid, &itemName,
+		&sale, &size, &totalPrice, &nmID, &brand, &status, &state,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	ord := &entity.OrderInfo{
+		OrderUID:          ordUID,
+		TrackNumber:       trackNumber,
+		Entry:             entry,
+		Locale:            locale,
+		InternalSignature: internalSig,
+		CustomerID:        customerID,
+		DeliveryService:   delivSvc,
+		ShardKey:          shardkey,
+		SmID:              smID,
+		DateCreated:       dateCreated.UTC(),
+		OofShard:          oofShard,
+		Status:            entity.OrderStatus(ordStatus),
+	}
+	ord.Delivery = entity.DeliveryInfo{
+		Name:    dName.String,
+		Phone:   dPhone.String,
+		Zip:     dZip.String,
+		City:    dCity.String,
+		Address: dAddr.String,
+		Region:  dRegion.String,
+		Email:   dEmail.String,
+	}
+	ord.Payment = entity.PaymentInfo{
+		Transaction:  pTrans.String,
+		RequestID:    pReqID.String,
+		Currency:     pCurr.String,
+		Provider:     pProv.String,
+		Amount:       pAmount.Int64,
+		Bank:         pBank.String,
+		DeliveryCost: pDelCost.Int64,
+		GoodsTotal:   pGoodsTotal.Int64,
+		CustomFee:    pCustomFee.Int64,
+		PaymentDT:    pPaidDt.Int64,
+	}
+	ord.Items = make([]entity.ItemInfo, 0, 2)
+
+	if !chrtID.Valid {
+		return ord, nil, nil
+	}
+	return ord, &entity.ItemInfo{
+		ChrtID:      chrtID.Int64,
+		TrackNumber: itemTrack.String,
+		Price:       price.Int64,
+		Rid:         rid.String,
+		Name:        itemName.String,
+		Sale:        sale.Int64,
+		Size:        size.String,
+		TotalPrice:  totalPrice.Int64,
+		NmID:        nmID.Int64,
+		Brand:       brand.String,
+		Status:      status.Int64,
+		State:       entity.OrderStatus(state.String),
+	}, nil
+}
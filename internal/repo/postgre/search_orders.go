@@ -0,0 +1,167 @@
+package postgre
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+const selectOrdersByUIDs = `
+	SELECT` + selectOrderColumns + `
+	FROM orders o
+	LEFT JOIN deliveries d ON d.order_uid = o.order_uid
+	LEFT JOIN payments   p ON p.order_uid = o.order_uid
+	LEFT JOIN items      i ON i.order_uid = o.order_uid
+	WHERE o.order_uid = ANY($1)
+	ORDER BY o.created_at DESC, o.order_uid DESC, i.chrt_id;
+`
+
+// orderKey — ключ keyset-пагинации одной найденной строки.
+type orderKey struct {
+	uid    string
+	cursor entity.OrderCursor
+}
+
+// buildSearchQuery собирает запрос ключей страницы по заданным фильтрам.
+// Возвращает limit+1 строк, чтобы понять, есть ли следующая страница.
+func buildSearchQuery(f entity.OrderFilter) (string, []any) {
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	sb.WriteString("SELECT o.order_uid, o.created_at FROM orders o")
+	if f.PaymentProvider != "" || f.PaymentBank != "" {
+		sb.WriteString(" JOIN payments p ON p.order_uid = o.order_uid")
+	}
+
+	where := filterConditions(f, arg)
+	if f.After != nil {
+		where = append(where, "(o.created_at, o.order_uid) < ("+arg(f.After.CreatedAt)+", "+arg(f.After.OrderUID)+")")
+	}
+
+	if len(where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(where, " AND "))
+	}
+	sb.WriteString(" ORDER BY o.created_at DESC, o.order_uid DESC LIMIT ")
+	sb.WriteString(arg(f.Limit + 1))
+
+	return sb.String(), args
+}
+
+// filterConditions возвращает условия фильтра для запроса по orders o;
+// условия на оплату ожидают, что payments присоединены как p.
+// Курсор и лимит сюда не входят.
+func filterConditions(f entity.OrderFilter, arg func(any) string) []string {
+	var where []string
+
+	if f.PaymentProvider != "" {
+		where = append(where, "p.provider = "+arg(f.PaymentProvider))
+	}
+	if f.PaymentBank != "" {
+		where = append(where, "p.bank = "+arg(f.PaymentBank))
+	}
+	if f.CustomerID != "" {
+		where = append(where, "o.customer_id = "+arg(f.CustomerID))
+	}
+	if f.TrackNumber != "" {
+		where = append(where, "o.track_number = "+arg(f.TrackNumber))
+	}
+	if f.DeliveryService != "" {
+		where = append(where, "o.delivery_service = "+arg(f.DeliveryService))
+	}
+	if !f.DateFrom.IsZero() {
+		where = append(where, "o.date_created >= "+arg(f.DateFrom))
+	}
+	if !f.DateTo.IsZero() {
+		where = append(where, "o.date_created < "+arg(f.DateTo))
+	}
+
+	if f.ItemNmID != 0 || f.ItemBrand != "" {
+		var itemConds []string
+		if f.ItemNmID != 0 {
+			itemConds = append(itemConds, "i.nm_id = "+arg(f.ItemNmID))
+		}
+		if f.ItemBrand != "" {
+			itemConds = append(itemConds, "i.brand = "+arg(f.ItemBrand))
+		}
+		where = append(where, "EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND "+
+			strings.Join(itemConds, " AND ")+")")
+	}
+
+	return where
+}
+
+// SearchOrders ищет заказы по фильтрам с keyset-пагинацией по (created_at, order_uid).
+func (rr *RatingRepository) SearchOrders(ctx context.Context, f entity.OrderFilter) (*entity.OrderPage, error) {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	logger := rr.log.With(zap.String("func", "SearchOrders"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	// 1) ключи страницы
+	query, args := buildSearchQuery(f)
+	rows, err := rr.pg.Pool.Query(ctx, query, args...)
+	if err != nil {
+		logger.Error("search query failed", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+	keys := make([]orderKey, 0, f.Limit+1)
+	for rows.Next() {
+		var k orderKey
+		if err := rows.Scan(&k.uid, &k.cursor.CreatedAt); err != nil {
+			rows.Close()
+			logger.Error("scan failed", zap.Error(err))
+			return nil, entity.ErrorQueryFailed
+		}
+		k.cursor.OrderUID = k.uid
+		keys = append(keys, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		logger.Error("rows error", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+
+	page := &entity.OrderPage{}
+	if len(keys) > f.Limit {
+		keys = keys[:f.Limit]
+		next := keys[len(keys)-1].cursor
+		page.Next = &next
+	}
+	if len(keys) == 0 {
+		page.Orders = []*entity.OrderInfo{}
+		return page, nil
+	}
+
+	// 2) полные заказы страницы
+	uids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		uids = append(uids, k.uid)
+	}
+	rows, err = rr.pg.Pool.Query(ctx, selectOrdersByUIDs, uids)
+	if err != nil {
+		logger.Error("query failed", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+	defer rows.Close()
+
+	page.Orders, err = scanOrders(rows, len(uids))
+	if err != nil {
+		logger.Error("scan failed", zap.Error(err))
+		return nil, entity.ErrorQueryFailed
+	}
+
+	logger.Info("search completed", zap.Int("found", len(page.Orders)), zap.Bool("has_next", page.Next != nil))
+	return page, nil
+}
@@ -0,0 +1,36 @@
+package postgre
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	after := &entity.OrderCursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), OrderUID: "b563feb7b2b84b6test"}
+
+	query, args := buildSearchQuery(entity.OrderFilter{
+		CustomerID:  "test",
+		PaymentBank: "alpha",
+		ItemBrand:   "Vivienne Sabo",
+		Limit:       10,
+		After:       after,
+	})
+
+	require.Equal(t, "SELECT o.order_uid, o.created_at FROM orders o"+
+		" JOIN payments p ON p.order_uid = o.order_uid"+
+		" WHERE p.bank = $1 AND o.customer_id = $2"+
+		" AND EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = $3)"+
+		" AND (o.created_at, o.order_uid) < ($4, $5)"+
+		" ORDER BY o.created_at DESC, o.order_uid DESC LIMIT $6", query)
+	require.Equal(t, []any{"alpha", "test", "Vivienne Sabo", after.CreatedAt, after.OrderUID, 11}, args)
+}
+
+func TestBuildSearchQueryWithoutFilters(t *testing.T) {
+	query, args := buildSearchQuery(entity.OrderFilter{Limit: 50})
+
+	require.Equal(t, "SELECT o.order_uid, o.created_at FROM orders o ORDER BY o.created_at DESC, o.order_uid DESC LIMIT $1", query)
+	require.Equal(t, []any{51}, args)
+}
@@ -0,0 +1,132 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	selectOrderStatusQuery = `SELECT status FROM orders WHERE order_uid = $1`
+	selectItemStateQuery   = `SELECT state FROM items WHERE order_uid = $1 AND chrt_id = $2 LIMIT 1`
+
+	// compare-and-set: обновляем, только если статус не изменился с момента чтения
+	updateOrderStatusQuery = `
+		UPDATE orders SET status = $3
+		WHERE order_uid = $1 AND status = $2
+	`
+	// у items нет первичного ключа: одну позицию адресует пара (order_uid, chrt_id),
+	// уникальность chrt_id внутри заказа проверяет валидация заказа
+	updateItemStateQuery = `
+		UPDATE items SET state = $4
+		WHERE order_uid = $1 AND chrt_id = $2 AND state = $3
+	`
+	insertStatusHistoryQuery = `
+		INSERT INTO order_status_history (
+			order_uid, chrt_id, from_status, to_status, reason, source, request_id)
+		VALUES ($1, NULLIF($2, 0), $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''))
+		RETURNING changed_at
+	`
+)
+
+// GetOrderStatus возвращает текущий статус заказа или, если chrtID != 0, его позиции.
+func (rr *RatingRepository) GetOrderStatus(ctx context.Context, orderUID string, chrtID int64) (entity.OrderStatus, error) {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	logger := rr.log.With(zap.String("func", "GetOrderStatus"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	var (
+		status string
+		row    pgx.Row
+	)
+	if chrtID == 0 {
+		row = rr.pg.Pool.QueryRow(ctx, selectOrderStatusQuery, orderUID)
+	} else {
+		row = rr.pg.Pool.QueryRow(ctx, selectItemStateQuery, orderUID, chrtID)
+	}
+	if err := row.Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Info("order not found", zap.String("order_uid", orderUID), zap.Int64("chrt_id", chrtID))
+			return "", entity.ErrorOrderNotFound
+		}
+		logger.Error("query failed", zap.Error(err))
+		return "", entity.ErrorQueryFailed
+	}
+
+	return entity.OrderStatus(status), nil
+}
+
+// UpdateOrderStatus применяет переход change.From -> change.To и пишет его в историю
+// одной транзакцией. Если статус уже не равен change.From, возвращает ErrStatusConflict.
+func (rr *RatingRepository) UpdateOrderStatus(ctx context.Context, change *entity.StatusChange) error {
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	logger := rr.log.With(zap.String("func", "UpdateOrderStatus"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	tx, err := rr.pg.Pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		logger.Error("begin tx failed", zap.Error(err))
+		return entity.ErrorDBConnect
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// 1) сам переход
+	var (
+		query string
+		args  []any
+	)
+	if change.ChrtID == 0 {
+		query, args = updateOrderStatusQuery, []any{change.OrderUID, string(change.From), string(change.To)}
+	} else {
+		query, args = updateItemStateQuery, []any{change.OrderUID, change.ChrtID, string(change.From), string(change.To)}
+	}
+	cmdTg, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		logger.Error("update status failed", zap.Error(err))
+		return entity.ErrorInsertDB
+	}
+	if cmdTg.RowsAffected() == 0 {
+		logger.Info("status changed concurrently",
+			zap.String("order_uid", change.OrderUID),
+			zap.Int64("chrt_id", change.ChrtID),
+			zap.String("expected", string(change.From)),
+		)
+		return entity.ErrStatusConflict
+	}
+
+	// 2) история
+	if err := tx.QueryRow(ctx, insertStatusHistoryQuery,
+		change.OrderUID, change.ChrtID, string(change.From), string(change.To),
+		change.Reason, change.Source, change.RequestID,
+	).Scan(&change.ChangedAt); err != nil {
+		logger.Error("insert status history failed", zap.Error(err))
+		return entity.ErrorInsertDB
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("commit failed", zap.Error(err))
+		return entity.ErrorInsertDB
+	}
+
+	change.ChangedAt = change.ChangedAt.UTC()
+	logger.Info("status updated",
+		zap.String("order_uid", change.OrderUID),
+		zap.Int64("chrt_id", change.ChrtID),
+		zap.String("from", string(change.From)),
+		zap.String("to", string(change.To)),
+	)
+	return nil
+}
@@ -0,0 +1,210 @@
+// Package rediscache реализует двухуровневый кэш заказов: локальный LRU
+// поверх общего для всех реплик Redis.
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	prom_metrics "github.com/RozmiDan/wb_tech_testtask/internal/metrics"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	_defaultTTL       = 10 * time.Minute
+	_defaultOpTimeout = 100 * time.Millisecond
+	_defaultCooldown  = 5 * time.Second
+	// версия в префиксе позволяет менять формат без чистки Redis
+	_defaultPrefix = "order:v1:"
+	_purgeBatch    = 500
+)
+
+// LocalCache — локальный уровень, тот же набор операций, что и usecase.OrderCache.
+type LocalCache interface {
+	Put(key string, val *entity.OrderResponse)
+	Get(key string) *entity.OrderResponse
+	Peek(key string) (*entity.OrderResponse, bool)
+	Delete(key string) bool
+	Purge()
+}
+
+// Layered — read-through/write-through кэш: чтение идёт в локальный LRU,
+// при промахе — в Redis с заполнением LRU; запись идёт в оба уровня.
+// Ошибка Redis не пробрасывается наружу: уровень отключается на cooldown,
+// и кэш продолжает работать как локальный.
+type Layered struct {
+	local     LocalCache
+	rdb       redis.UniversalClient
+	prefix    string
+	ttl       time.Duration
+	opTimeout time.Duration
+	cooldown  time.Duration
+	downUntil atomic.Int64 // unix nano, до которого Redis не опрашивается
+	log       *zap.Logger
+}
+
+func New(local LocalCache, rdb redis.UniversalClient, logger *zap.Logger, opts ...Option) *Layered {
+	c := &Layered{
+		local:     local,
+		rdb:       rdb,
+		prefix:    _defaultPrefix,
+		ttl:       _defaultTTL,
+		opTimeout: _defaultOpTimeout,
+		cooldown:  _defaultCooldown,
+		log:       logger.With(zap.String("component", "shared_cache")),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Layered) Get(key string) *entity.OrderResponse {
+	if v := c.local.Get(key); v != nil {
+		return v
+	}
+	if !c.available("get") {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	b, err := c.rdb.Get(ctx, c.prefix+key).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		prom_metrics.CacheSharedRequests.WithLabelValues("get", "miss").Inc()
+		return nil
+	case err != nil:
+		c.markDown("get", err)
+		return nil
+	}
+
+	order := &entity.OrderResponse{}
+	if err := json.Unmarshal(b, order); err != nil {
+		// битое значение не должно отдаваться повторно
+		c.log.Warn("invalid cached value, dropping", zap.String("key", key), zap.Error(err))
+		c.rdb.Del(ctx, c.prefix+key)
+		prom_metrics.CacheSharedRequests.WithLabelValues("get", "error").Inc()
+		return nil
+	}
+
+	prom_metrics.CacheSharedRequests.WithLabelValues("get", "hit").Inc()
+	c.local.Put(key, order)
+	return order
+}
+
+func (c *Layered) Put(key string, val *entity.OrderResponse) {
+	c.local.Put(key, val)
+	if val == nil || !c.available("set") {
+		return
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		c.log.Error("marshal cached value failed", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	if err := c.rdb.Set(ctx, c.prefix+key, b, c.ttl).Err(); err != nil {
+		c.markDown("set", err)
+		return
+	}
+	prom_metrics.CacheSharedRequests.WithLabelValues("set", "ok").Inc()
+}
+
+// Peek смотрит только в локальный уровень.
+func (c *Layered) Peek(key string) (*entity.OrderResponse, bool) {
+	return c.local.Peek(key)
+}
+
+func (c *Layered) Delete(key string) bool {
+	removed := c.local.Delete(key)
+	if !c.available("del") {
+		return removed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	n, err := c.rdb.Del(ctx, c.prefix+key).Result()
+	if err != nil {
+		c.markDown("del", err)
+		return removed
+	}
+	prom_metrics.CacheSharedRequests.WithLabelValues("del", "ok").Inc()
+	return removed || n > 0
+}
+
+// Purge очищает локальный уровень и все ключи заказов в Redis.
+func (c *Layered) Purge() {
+	c.local.Purge()
+	if !c.available("purge") {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*c.opTimeout)
+	defer cancel()
+
+	iter := c.rdb.Scan(ctx, 0, c.prefix+"*", _purgeBatch).Iterator()
+	keys := make([]string, 0, _purgeBatch)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		err := c.rdb.Del(ctx, keys...).Err()
+		keys = keys[:0]
+		return err
+	}
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == _purgeBatch {
+			if err := flush(); err != nil {
+				c.markDown("purge", err)
+				return
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		c.markDown("purge", err)
+		return
+	}
+	if err := flush(); err != nil {
+		c.markDown("purge", err)
+		return
+	}
+	prom_metrics.CacheSharedRequests.WithLabelValues("purge", "ok").Inc()
+}
+
+// Ping проверяет доступность Redis; для диагностики, не для readiness:
+// без Redis сервис работает на локальном кэше.
+func (c *Layered) Ping(ctx context.Context) error {
+	return c.rdb.Ping(ctx).Err()
+}
+
+func (c *Layered) available(op string) bool {
+	if time.Now().UnixNano() < c.downUntil.Load() {
+		prom_metrics.CacheSharedRequests.WithLabelValues(op, "skipped").Inc()
+		return false
+	}
+	return true
+}
+
+func (c *Layered) markDown(op string, err error) {
+	prom_metrics.CacheSharedRequests.WithLabelValues(op, "error").Inc()
+	until := time.Now().Add(c.cooldown)
+	c.downUntil.Store(until.UnixNano())
+	c.log.Warn("shared cache unavailable, falling back to local",
+		zap.String("op", op),
+		zap.Duration("cooldown", c.cooldown),
+		zap.Error(err),
+	)
+}
@@ -0,0 +1,93 @@
+package rediscache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newLayered(t *testing.T, mr *miniredis.Miniredis, opts ...Option) *Layered {
+	t.Helper()
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	local := lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil)
+	return New(local, rdb, zap.NewNop(), opts...)
+}
+
+func testOrder(uid string) *entity.OrderResponse {
+	return &entity.OrderResponse{
+		OrderUID:    uid,
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Status:      entity.StatusCreated,
+		Items:       []entity.ItemPublic{{ChrtID: 9934930, Name: "Mascaras", State: entity.StatusCreated}},
+	}
+}
+
+func TestLayeredSharesOrdersBetweenReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a := newLayered(t, mr, TTL(time.Minute))
+	b := newLayered(t, mr)
+
+	a.Put("b563feb7b2b84b6test", testOrder("b563feb7b2b84b6test"))
+	require.True(t, mr.Exists(_defaultPrefix+"b563feb7b2b84b6test"), "write-through")
+	require.Equal(t, time.Minute, mr.TTL(_defaultPrefix+"b563feb7b2b84b6test"))
+
+	// вторая реплика читает из Redis и заполняет свой LRU
+	got := b.Get("b563feb7b2b84b6test")
+	require.Equal(t, testOrder("b563feb7b2b84b6test"), got)
+	_, ok := b.Peek("b563feb7b2b84b6test")
+	require.True(t, ok, "read-through populates local tier")
+
+	require.Nil(t, b.Get("missing"))
+}
+
+func TestLayeredDeleteAndPurge(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newLayered(t, mr)
+	require.NoError(t, mr.Set("unrelated", "keep"))
+
+	c.Put("a", testOrder("a"))
+	c.Put("b", testOrder("b"))
+
+	require.True(t, c.Delete("a"))
+	require.False(t, mr.Exists(_defaultPrefix+"a"))
+	require.False(t, c.Delete("a"))
+
+	c.Purge()
+	require.False(t, mr.Exists(_defaultPrefix+"b"))
+	require.True(t, mr.Exists("unrelated"), "purge touches only order keys")
+	require.Nil(t, c.Get("b"))
+}
+
+func TestLayeredFallsBackWhenSharedTierIsDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newLayered(t, mr, Cooldown(time.Hour), OpTimeout(50*time.Millisecond))
+
+	mr.Close()
+
+	c.Put("a", testOrder("a"))
+	require.Equal(t, "a", c.Get("a").OrderUID, "local tier keeps working")
+	require.Nil(t, c.Get("missing"))
+
+	// во время cooldown Redis не опрашивается даже после восстановления
+	require.NoError(t, mr.Restart())
+	c.Put("b", testOrder("b"))
+	require.False(t, mr.Exists(_defaultPrefix+"b"))
+}
+
+func TestLayeredDropsCorruptedValue(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newLayered(t, mr)
+	require.NoError(t, mr.Set(_defaultPrefix+"a", "{not json"))
+
+	require.Nil(t, c.Get("a"))
+	require.False(t, mr.Exists(_defaultPrefix+"a"))
+}
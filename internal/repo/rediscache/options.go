@@ -0,0 +1,34 @@
+package rediscache
+
+import "time"
+
+// Option -.
+type Option func(*Layered)
+
+// TTL задаёт время жизни записи в Redis.
+func TTL(ttl time.Duration) Option {
+	return func(c *Layered) {
+		c.ttl = ttl
+	}
+}
+
+// OpTimeout ограничивает одну операцию с Redis.
+func OpTimeout(timeout time.Duration) Option {
+	return func(c *Layered) {
+		c.opTimeout = timeout
+	}
+}
+
+// Cooldown — сколько не обращаться к Redis после ошибки.
+func Cooldown(d time.Duration) Option {
+	return func(c *Layered) {
+		c.cooldown = d
+	}
+}
+
+// KeyPrefix задаёт префикс ключей заказов.
+func KeyPrefix(prefix string) Option {
+	return func(c *Layered) {
+		c.prefix = prefix
+	}
+}
@@ -18,11 +18,11 @@ func (u *UsecaseLayer) AddOrderInfo(ctx context.Context, order *entity.OrderInfo
 		logger = logger.With(zap.String("request_id", reqID))
 	}
 
-	// 3) валидируем order
+	// 3) валидируем order, нарушения отдаём наверх как есть (*entity.ValidationError)
 	if err := order.ValidateOrder(); err != nil {
 		logger.Warn("invalid order payload", zap.Error(err))
 
-		return entity.ErrInvalidInput
+		return err
 	}
 
 	// 4) записываем в бд
@@ -48,6 +48,8 @@ func (u *UsecaseLayer) AddOrderInfo(ctx context.Context, order *entity.OrderInfo
 	}
 	// 5) пишем в кэш
 	u.cache.Put(order.OrderUID, mapOrderToResponse(order))
+	u.invalidateRefs(order)
+	u.forgetNotFound(order.OrderUID)
 
 	logger.Info("succsessfuly add order", zap.String("order_uid", order.OrderUID))
 
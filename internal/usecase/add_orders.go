@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// AddOrders валидирует и сохраняет пачку заказов одной транзакцией.
+// Результаты возвращаются в порядке входного среза.
+func (u *UsecaseLayer) AddOrders(ctx context.Context, orders []*entity.OrderInfo) []entity.OrderResult {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "AddOrders"), zap.Int("batch_size", len(orders)))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	// 3) валидируем, невалидные в бд не отправляем
+	results := make([]entity.OrderResult, len(orders))
+	valid := make([]*entity.OrderInfo, 0, len(orders))
+	validIdx := make([]int, 0, len(orders))
+	for i, o := range orders {
+		if err := o.ValidateOrder(); err != nil {
+			logger.Warn("invalid order payload", zap.Int("index", i), zap.Error(err))
+			results[i] = entity.OrderResult{Outcome: entity.OutcomeInvalid, Err: err}
+			if o != nil {
+				results[i].OrderUID = o.OrderUID
+			}
+			continue
+		}
+		valid = append(valid, o)
+		validIdx = append(validIdx, i)
+	}
+	if len(valid) == 0 {
+		return results
+	}
+
+	// 4) записываем в бд
+	repoRes, err := u.db.SetOrders(ctx, valid)
+	if err != nil {
+		logger.Error("batch insert failed", zap.Error(err))
+	}
+
+	// 5) переводим ошибки репозитория в ошибки usecase и пишем новые заказы в кэш
+	inserted := 0
+	for j, res := range repoRes {
+		switch res.Outcome {
+		case entity.OutcomeInserted:
+			res.Err = nil
+			u.cache.Put(valid[j].OrderUID, mapOrderToResponse(valid[j]))
+			u.invalidateRefs(valid[j])
+			u.forgetNotFound(valid[j].OrderUID)
+			inserted++
+		case entity.OutcomeAlreadyExists:
+			res.Err = entity.ErrAlreadyExists
+		default:
+			res.Err = entity.ErrInternal
+		}
+		results[validIdx[j]] = res
+	}
+
+	logger.Info("orders batch processed", zap.Int("inserted", inserted))
+
+	return results
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// batchRepo вставляет пачку как SetOrders из postgre: существующие order_uid —
+// already_exists, при err вся пачка failed.
+type batchRepo struct {
+	RepoLayer
+	existing map[string]bool
+	err      error
+	batches  [][]*entity.OrderInfo
+}
+
+func (r *batchRepo) SetOrders(_ context.Context, orders []*entity.OrderInfo) ([]entity.OrderResult, error) {
+	r.batches = append(r.batches, orders)
+	results := make([]entity.OrderResult, len(orders))
+	for i, o := range orders {
+		results[i] = entity.OrderResult{OrderUID: o.OrderUID, Outcome: entity.OutcomeInserted}
+		switch {
+		case r.err != nil:
+			results[i].Outcome, results[i].Err = entity.OutcomeFailed, r.err
+		case r.existing[o.OrderUID]:
+			results[i].Outcome = entity.OutcomeAlreadyExists
+		}
+	}
+	return results, r.err
+}
+
+func TestAddOrders(t *testing.T) {
+	repo := &batchRepo{existing: map[string]bool{"old": true}}
+	cache := lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil)
+	uc := New(zap.NewNop(), repo, cache)
+
+	invalid := validOrder("bad")
+	invalid.Payment.Amount = 0
+
+	res := uc.AddOrders(context.Background(), []*entity.OrderInfo{validOrder("new"), invalid, validOrder("old")})
+	require.Len(t, res, 3)
+
+	require.Equal(t, "new", res[0].OrderUID)
+	require.Equal(t, entity.OutcomeInserted, res[0].Outcome)
+	require.NoError(t, res[0].Err)
+
+	require.Equal(t, "bad", res[1].OrderUID)
+	require.Equal(t, entity.OutcomeInvalid, res[1].Outcome)
+	require.ErrorIs(t, res[1].Err, entity.ErrInvalidInput)
+
+	require.Equal(t, "old", res[2].OrderUID)
+	require.Equal(t, entity.OutcomeAlreadyExists, res[2].Outcome)
+	require.ErrorIs(t, res[2].Err, entity.ErrAlreadyExists)
+
+	require.Len(t, repo.batches, 1)
+	require.Len(t, repo.batches[0], 2, "invalid orders never reach the db")
+	require.NotNil(t, cache.Get("new"), "inserted order is cached")
+	require.Nil(t, cache.Get("old"), "existing order is not overwritten in the cache")
+}
+
+func TestAddOrdersAllInvalidSkipsDB(t *testing.T) {
+	repo := &batchRepo{}
+	uc := New(zap.NewNop(), repo, lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil))
+
+	res := uc.AddOrders(context.Background(), []*entity.OrderInfo{nil, {OrderUID: "empty"}})
+	require.Len(t, res, 2)
+	for _, r := range res {
+		require.Equal(t, entity.OutcomeInvalid, r.Outcome)
+	}
+	require.Equal(t, "empty", res[1].OrderUID)
+	require.Empty(t, repo.batches)
+}
+
+func TestAddOrdersBatchFailure(t *testing.T) {
+	repo := &batchRepo{err: entity.ErrorDBConnect}
+	cache := lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil)
+	uc := New(zap.NewNop(), repo, cache)
+
+	res := uc.AddOrders(context.Background(), []*entity.OrderInfo{validOrder("a"), validOrder("b")})
+	for _, r := range res {
+		require.Equal(t, entity.OutcomeFailed, r.Outcome)
+		require.ErrorIs(t, r.Err, entity.ErrInternal, "repo errors are not leaked to the caller")
+	}
+	require.Nil(t, cache.Get("a"))
+}
+
+func TestAddOrdersForgetsNotFound(t *testing.T) {
+	notFound := lru_cache.NewLruCache[string](10, struct{}{})
+	notFound.Put("a", struct{}{})
+	uc := New(zap.NewNop(), &batchRepo{}, lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil),
+		WithNotFoundCache(notFound))
+
+	res := uc.AddOrders(context.Background(), []*entity.OrderInfo{validOrder("a")})
+	require.Equal(t, entity.OutcomeInserted, res[0].Outcome)
+	require.False(t, notFound.Contains("a"))
+}
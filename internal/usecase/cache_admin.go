@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// EvictOrder удаляет заказ из кэша. Следующий запрос прочитает его из бд.
+func (u *UsecaseLayer) EvictOrder(ctx context.Context, orderUID string) (bool, error) {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "EvictOrder"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	if orderUID == "" {
+		logger.Warn("empty order_uid")
+
+		return false, entity.ErrInvalidInput
+	}
+
+	// вторичные ключи заказа неизвестны без чтения из кэша/бд; записи с этим
+	// order_uid станут промахом в cachedRefs и перечитаются при следующем обращении
+	removed := u.cache.Delete(orderUID)
+	logger.Info("order evicted from cache", zap.String("order_uid", orderUID), zap.Bool("removed", removed))
+
+	return removed, nil
+}
+
+// PurgeCache очищает основной и вторичный кэши.
+func (u *UsecaseLayer) PurgeCache(ctx context.Context) {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "PurgeCache"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	u.cache.Purge()
+	if u.refs != nil {
+		u.refs.Purge()
+	}
+	if u.notFound != nil {
+		u.notFound.Purge()
+	}
+	logger.Info("cache purged")
+}
+
+// forgetNotFound убирает order_uid из негативного кэша, когда заказ появился.
+func (u *UsecaseLayer) forgetNotFound(orderUID string) {
+	u.missVer.bump(orderUID)
+	if u.notFound != nil {
+		u.notFound.Delete(orderUID)
+	}
+}
+
+// rememberNotFound кэширует промах, прочитанный из бд при версии ver.
+// Если заказ успели добавить после чтения, промах уже устарел и убирается.
+func (u *UsecaseLayer) rememberNotFound(orderUID string, ver uint64) {
+	u.notFound.Put(orderUID, struct{}{})
+	if u.missVer.of(orderUID) != ver {
+		u.notFound.Delete(orderUID)
+	}
+}
+
+// cacheRead кладёт в кэш заказ, прочитанный из бд при версии ver. Если заказ
+// изменился во время чтения, результат убирается: иначе старый статус
+// остался бы в кэше до вытеснения.
+func (u *UsecaseLayer) cacheRead(dto *entity.OrderResponse, ver uint64) {
+	u.cache.Put(dto.OrderUID, dto)
+	if u.staleVer.of(dto.OrderUID) != ver {
+		u.cache.Delete(dto.OrderUID)
+	}
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// ExportOrders выгружает все заказы под фильтр, отдавая их в fn по одному
+// в порядке (created_at DESC, order_uid DESC). Заказы читаются курсором из бд
+// мимо кэша. Ошибка fn (например, клиент отключился) прерывает выгрузку и
+// возвращается как есть.
+func (u *UsecaseLayer) ExportOrders(ctx context.Context, filter entity.OrderFilter, fn func(*entity.OrderResponse) error) error {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "ExportOrders"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	// 3) проверяем фильтр; пагинация в выгрузке не используется
+	filter.Limit, filter.After = 0, nil
+	if !filter.DateFrom.IsZero() && !filter.DateTo.IsZero() && !filter.DateFrom.Before(filter.DateTo) {
+		logger.Warn("invalid date range", zap.Time("date_from", filter.DateFrom), zap.Time("date_to", filter.DateTo))
+
+		return entity.ErrInvalidInput
+	}
+
+	// 4) выгружаем из бд
+	var (
+		exported int
+		fnErr    error
+	)
+	err := u.db.ExportOrders(ctx, filter, func(o *entity.OrderInfo) error {
+		if fnErr = fn(mapOrderToResponse(o)); fnErr != nil {
+			return fnErr
+		}
+		exported++
+		return nil
+	})
+	switch {
+	case err == nil:
+	case fnErr != nil:
+		logger.Warn("export interrupted", zap.Int("exported", exported), zap.Error(fnErr))
+
+		return fnErr
+	default:
+		logger.Error("export failed", zap.Int("exported", exported), zap.Error(err))
+
+		return entity.ErrInternal
+	}
+
+	logger.Info("export completed", zap.Int("exported", exported))
+
+	return nil
+}
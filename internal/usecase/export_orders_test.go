@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type exportRepo struct {
+	RepoLayer
+	orders []*entity.OrderInfo
+	filter entity.OrderFilter
+	err    error
+}
+
+func (r *exportRepo) ExportOrders(_ context.Context, f entity.OrderFilter, fn func(*entity.OrderInfo) error) error {
+	r.filter = f
+	for _, o := range r.orders {
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+func newExportUsecase(repo *exportRepo) *UsecaseLayer {
+	return New(zap.NewNop(), repo, lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil))
+}
+
+func TestExportOrdersStreamsPublicOrders(t *testing.T) {
+	repo := &exportRepo{orders: []*entity.OrderInfo{
+		{OrderUID: "a", InternalSignature: "secret", Items: []entity.ItemInfo{{ChrtID: 1}}},
+		{OrderUID: "b"},
+	}}
+	uc := newExportUsecase(repo)
+
+	var got []*entity.OrderResponse
+	err := uc.ExportOrders(context.Background(), entity.OrderFilter{
+		CustomerID: "test",
+		Limit:      10,
+		After:      &entity.OrderCursor{OrderUID: "x"},
+	}, func(o *entity.OrderResponse) error {
+		got = append(got, o)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, got, 2)
+	require.Equal(t, "a", got[0].OrderUID)
+	require.Equal(t, entity.StatusCreated, got[0].Items[0].State)
+	require.Equal(t, entity.OrderFilter{CustomerID: "test"}, repo.filter, "pagination is dropped")
+}
+
+func TestExportOrdersErrors(t *testing.T) {
+	sink := func(*entity.OrderResponse) error { return nil }
+
+	repo := &exportRepo{}
+	now := time.Now()
+	err := newExportUsecase(repo).ExportOrders(context.Background(), entity.OrderFilter{DateFrom: now, DateTo: now}, sink)
+	require.ErrorIs(t, err, entity.ErrInvalidInput)
+
+	repo = &exportRepo{err: entity.ErrorQueryFailed}
+	err = newExportUsecase(repo).ExportOrders(context.Background(), entity.OrderFilter{}, sink)
+	require.ErrorIs(t, err, entity.ErrInternal)
+
+	// ошибка получателя возвращается как есть, а не как ошибка бд
+	errGone := errors.New("client gone")
+	repo = &exportRepo{orders: []*entity.OrderInfo{{OrderUID: "a"}}}
+	err = newExportUsecase(repo).ExportOrders(context.Background(), entity.OrderFilter{}, func(*entity.OrderResponse) error {
+		return errGone
+	})
+	require.ErrorIs(t, err, errGone)
+}
@@ -21,17 +21,33 @@ func (u *UsecaseLayer) WarmCacheLatest(ctx context.Context, cacheCap int) error
 
 		return errors.New("invalid cache capacity value")
 	}
+	ver := u.staleVer.all()
 	orders, err := u.db.GetLatestOrders(ctx, cacheCap)
 	if err != nil {
 		logger.Error("Cant find values", zap.Int("count", len(orders)))
 
 		return err
 	}
+	// прогрев идёт параллельно с консьюмером: уже закэшированные заказы
+	// не перезаписываем, они не старее прочитанных сейчас
+	warmed := make([]string, 0, len(orders))
 	for _, o := range orders {
+		if _, ok := u.cache.Peek(o.OrderUID); ok {
+			continue
+		}
 		dto := mapOrderToResponse(o)
 		u.cache.Put(dto.OrderUID, dto)
+		warmed = append(warmed, dto.OrderUID)
 	}
-	logger.Info("cache warmed", zap.Int("count", len(orders)))
+	// статус какого-то заказа сменился во время чтения: прогрев не должен
+	// вернуть в кэш старый ответ
+	if u.staleVer.all() != ver {
+		for _, uid := range warmed {
+			u.cache.Delete(uid)
+		}
+		warmed = warmed[:0]
+	}
+	logger.Info("cache warmed", zap.Int("count", len(warmed)))
 
 	return nil
 }
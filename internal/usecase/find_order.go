@@ -6,6 +6,7 @@ import (
 
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 func (u *UsecaseLayer) GetOrderInfo(ctx context.Context, orderUID string) (*entity.OrderResponse, error) {
@@ -31,36 +32,61 @@ func (u *UsecaseLayer) GetOrderInfo(ctx context.Context, orderUID string) (*enti
 		return cached, nil
 	}
 
-	order, err := u.db.GetOrderByUID(ctx, orderUID)
-	if err != nil {
-		switch {
-		case errors.Is(err, entity.ErrorOrderNotFound):
-			logger.Info("order not found")
+	// недавно не нашли — не идём в бд
+	if u.notFound != nil && u.notFound.Contains(orderUID) {
+		logger.Info("order not found (negative cache)", zap.String("uid", orderUID))
+
+		return nil, entity.ErrorOrderNotFound
+	}
+
+	// одновременные промахи по одному order_uid ждут один запрос
+	ch := u.inflight.DoChan(orderUID, func() (any, error) {
+		return u.fetchOrder(ctx, orderUID)
+	})
+
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		logger.Error("ctx done while waiting for order", zap.Error(ctx.Err()))
+
+		return nil, entity.ErrInternal
+	}
+	if res.Err != nil {
+		if errors.Is(res.Err, entity.ErrorOrderNotFound) {
+			logger.Info("order not found", zap.Bool("shared", res.Shared))
 
 			return nil, entity.ErrorOrderNotFound
-		case errors.Is(err, entity.ErrorQueryFailed):
-			if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(ctx.Err(), context.Canceled) {
-				logger.Error("query failed due to ctx deadline/cancel", zap.Error(ctx.Err()))
-
-				return nil, entity.ErrInternal
-			}
-			logger.Error("query failed", zap.Error(err))
-			return nil, entity.ErrInternal
-		default:
-			if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(ctx.Err(), context.Canceled) {
-				logger.Error("unexpected repo error with ctx cancel/deadline", zap.Error(err), zap.Error(ctx.Err()))
-
-				return nil, entity.ErrInternal
-			}
-			logger.Error("unexpected repo error", zap.Error(err))
-
-			return nil, entity.ErrInternal
 		}
+		logger.Error("query failed", zap.Bool("shared", res.Shared), zap.Error(res.Err))
+
+		return nil, entity.ErrInternal
+	}
+
+	logger.Info("succsessfuly found order", zap.Bool("shared", res.Shared))
+
+	return res.Val.(*entity.OrderResponse), nil
+}
+
+// fetchOrder читает заказ из бд и кладёт результат в кэш (или в негативный кэш).
+// Запрос общий для всех ожидающих, поэтому не отменяется вместе с контекстом
+// первого клиента, а ограничен fetchTimeout.
+func (u *UsecaseLayer) fetchOrder(ctx context.Context, orderUID string) (*entity.OrderResponse, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), u.fetchTimeout)
+	defer cancel()
+
+	missVer, staleVer := u.missVer.of(orderUID), u.staleVer.of(orderUID)
+	order, err := u.db.GetOrderByUID(ctx, orderUID)
+	if err != nil {
+		if errors.Is(err, entity.ErrorOrderNotFound) && u.notFound != nil {
+			u.rememberNotFound(orderUID, missVer)
+		}
+
+		return nil, err
 	}
 
 	resOrd := mapOrderToResponse(order)
-	u.cache.Put(orderUID, resOrd)
-	logger.Info("succsessfuly found order")
+	u.cacheRead(resOrd, staleVer)
 
 	return resOrd, nil
 }
@@ -69,12 +95,14 @@ func mapOrderToResponse(order *entity.OrderInfo) *entity.OrderResponse {
 	items := make([]entity.ItemPublic, 0, len(order.Items))
 	for _, it := range order.Items {
 		items = append(items, entity.ItemPublic{
+			ChrtID:     it.ChrtID,
 			Name:       it.Name,
 			Brand:      it.Brand,
 			Size:       it.Size,
 			Price:      it.Price,
 			TotalPrice: it.TotalPrice,
 			Status:     it.Status,
+			State:      statusOrCreated(it.State),
 		})
 	}
 
@@ -82,6 +110,7 @@ func mapOrderToResponse(order *entity.OrderInfo) *entity.OrderResponse {
 		OrderUID:    order.OrderUID,
 		DateCreated: order.DateCreated,
 		Locale:      order.Locale,
+		Status:      statusOrCreated(order.Status),
 		Logistics: entity.LogisticsInfo{
 			TrackNumber:     order.TrackNumber,
 			DeliveryService: order.DeliveryService,
@@ -103,3 +132,12 @@ func mapOrderToResponse(order *entity.OrderInfo) *entity.OrderResponse {
 		Items: items,
 	}
 }
+
+// statusOrCreated подставляет начальный статус для только что принятых заказов,
+// которые ещё не читались из бд.
+func statusOrCreated(s entity.OrderStatus) entity.OrderStatus {
+	if s == "" {
+		return entity.StatusCreated
+	}
+	return s
+}
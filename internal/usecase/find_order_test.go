@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// slowRepo отвечает на GetOrderByUID после release, считая обращения.
+type slowRepo struct {
+	RepoLayer
+	release chan struct{}
+	calls   atomic.Int32
+	known   string
+}
+
+func (r *slowRepo) GetOrderByUID(_ context.Context, orderUID string) (*entity.OrderInfo, error) {
+	r.calls.Add(1)
+	<-r.release
+	if orderUID != r.known {
+		return nil, entity.ErrorOrderNotFound
+	}
+	return &entity.OrderInfo{OrderUID: orderUID}, nil
+}
+
+// SetOrder принимает заказ, не меняя ответов GetOrderByUID: так чтение из бд
+// остаётся «до» сохранения.
+func (r *slowRepo) SetOrder(context.Context, *entity.OrderInfo) error {
+	return nil
+}
+
+func TestGetOrderInfoCoalescesConcurrentMisses(t *testing.T) {
+	repo := &slowRepo{release: make(chan struct{}), known: "a"}
+	uc := New(zap.NewNop(), repo, lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil))
+
+	const callers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o, err := uc.GetOrderInfo(context.Background(), "a")
+			if err == nil && o.OrderUID != "a" {
+				err = entity.ErrInternal
+			}
+			errs <- err
+		}()
+	}
+
+	require.Eventually(t, func() bool { return repo.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond) // даём остальным встать в ожидание
+	close(repo.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), repo.calls.Load(), "one db round-trip for all callers")
+}
+
+func TestGetOrderInfoCallerCancelDoesNotFailOthers(t *testing.T) {
+	repo := &slowRepo{release: make(chan struct{}), known: "a"}
+	uc := New(zap.NewNop(), repo, lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := uc.GetOrderInfo(ctx, "a")
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool { return repo.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	follower := make(chan error, 1)
+	go func() {
+		_, err := uc.GetOrderInfo(context.Background(), "a")
+		follower <- err
+	}()
+
+	cancel()
+	require.ErrorIs(t, <-leaderErr, entity.ErrInternal)
+
+	close(repo.release)
+	require.NoError(t, <-follower)
+	require.Equal(t, int32(1), repo.calls.Load())
+}
+
+func TestGetOrderInfoNegativeCache(t *testing.T) {
+	repo := &slowRepo{release: make(chan struct{})}
+	close(repo.release)
+	notFound := lru_cache.NewLruCache(10, struct{}{}, lru_cache.WithTTL[string, struct{}](time.Minute))
+	uc := New(zap.NewNop(), repo, lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil),
+		WithNotFoundCache(notFound))
+
+	for range 3 {
+		_, err := uc.GetOrderInfo(context.Background(), "missing")
+		require.ErrorIs(t, err, entity.ErrorOrderNotFound)
+	}
+	require.Equal(t, int32(1), repo.calls.Load(), "404 served from negative cache")
+
+	uc.forgetNotFound("missing")
+	_, err := uc.GetOrderInfo(context.Background(), "missing")
+	require.ErrorIs(t, err, entity.ErrorOrderNotFound)
+	require.Equal(t, int32(2), repo.calls.Load())
+}
+
+func TestGetOrderInfoStaleMissNotCachedAfterAdd(t *testing.T) {
+	repo := &slowRepo{release: make(chan struct{})}
+	cache := lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil)
+	notFound := lru_cache.NewLruCache(10, struct{}{}, lru_cache.WithTTL[string, struct{}](time.Minute))
+	uc := New(zap.NewNop(), repo, cache, WithNotFoundCache(notFound))
+
+	// запрос прочитал бд до вставки, а ответ вернул уже после AddOrderInfo
+	fetched := make(chan error, 1)
+	go func() {
+		_, err := uc.GetOrderInfo(context.Background(), "late")
+		fetched <- err
+	}()
+	require.Eventually(t, func() bool { return repo.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, uc.AddOrderInfo(context.Background(), validOrder("late")))
+	close(repo.release)
+	require.ErrorIs(t, <-fetched, entity.ErrorOrderNotFound)
+
+	require.False(t, notFound.Contains("late"), "stale miss is not cached")
+}
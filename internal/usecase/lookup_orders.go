@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// ключи вторичного кэша
+const (
+	refsTrackPrefix       = "track:"
+	refsTransactionPrefix = "tx:"
+	refsCustomerPrefix    = "customer:"
+)
+
+func (u *UsecaseLayer) GetOrdersByTrack(ctx context.Context, trackNumber string) (*entity.OrderListResponse, error) {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "GetOrdersByTrack"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	if trackNumber == "" {
+		logger.Warn("empty track_number")
+
+		return nil, entity.ErrInvalidInput
+	}
+
+	// 3) смотрим во вторичный кэш
+	key := refsTrackPrefix + trackNumber
+	if orders, _, ok := u.cachedRefs(key); ok {
+		logger.Info("cache hit", zap.String("track_number", trackNumber))
+
+		return &entity.OrderListResponse{Orders: orders}, nil
+	}
+
+	// 4) ищем в бд
+	ver := u.staleVer.all()
+	found, err := u.db.GetOrdersByTrackNumber(ctx, trackNumber, entity.MaxPageLimit)
+	if err != nil {
+		logger.Error("query failed", zap.Error(err))
+
+		return nil, entity.ErrInternal
+	}
+
+	resp := &entity.OrderListResponse{Orders: u.cacheOrders(key, found, "", ver)}
+	if len(resp.Orders) == 0 {
+		logger.Info("orders not found", zap.String("track_number", trackNumber))
+
+		return nil, entity.ErrorOrderNotFound
+	}
+
+	logger.Info("succsessfuly found orders", zap.Int("found", len(resp.Orders)))
+
+	return resp, nil
+}
+
+func (u *UsecaseLayer) GetOrderByTransaction(ctx context.Context, transaction string) (*entity.OrderResponse, error) {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "GetOrderByTransaction"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	if transaction == "" {
+		logger.Warn("empty transaction")
+
+		return nil, entity.ErrInvalidInput
+	}
+
+	// 3) смотрим во вторичный кэш
+	key := refsTransactionPrefix + transaction
+	if orders, _, ok := u.cachedRefs(key); ok && len(orders) == 1 {
+		logger.Info("cache hit", zap.String("transaction", transaction))
+
+		return orders[0], nil
+	}
+
+	// 4) ищем в бд
+	ver := u.staleVer.all()
+	order, err := u.db.GetOrderByTransaction(ctx, transaction)
+	if err != nil {
+		if errors.Is(err, entity.ErrorOrderNotFound) {
+			logger.Info("order not found")
+
+			return nil, entity.ErrorOrderNotFound
+		}
+		logger.Error("query failed", zap.Error(err))
+
+		return nil, entity.ErrInternal
+	}
+
+	resOrd := u.cacheOrders(key, []*entity.OrderInfo{order}, "", ver)[0]
+	logger.Info("succsessfuly found order")
+
+	return resOrd, nil
+}
+
+// GetCustomerOrders возвращает заказы покупателя постранично.
+// Во вторичный кэш попадает только первая страница с размером по умолчанию.
+func (u *UsecaseLayer) GetCustomerOrders(ctx context.Context, customerID string, limit int, after *entity.OrderCursor) (*entity.OrderListResponse, error) {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "GetCustomerOrders"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	if customerID == "" {
+		logger.Warn("empty customer_id")
+
+		return nil, entity.ErrInvalidInput
+	}
+
+	// 3) смотрим во вторичный кэш
+	key := refsCustomerPrefix + customerID
+	cacheable := after == nil && limit <= 0
+	if cacheable {
+		if orders, next, ok := u.cachedRefs(key); ok {
+			logger.Info("cache hit", zap.String("customer_id", customerID))
+
+			return &entity.OrderListResponse{Orders: orders, NextCursor: next}, nil
+		}
+	}
+
+	// 4) ищем в бд
+	filter := entity.OrderFilter{CustomerID: customerID, Limit: limit, After: after}
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = entity.DefaultPageLimit
+	case filter.Limit > entity.MaxPageLimit:
+		filter.Limit = entity.MaxPageLimit
+	}
+	ver := u.staleVer.all()
+	page, err := u.db.SearchOrders(ctx, filter)
+	if err != nil {
+		logger.Error("query failed", zap.Error(err))
+
+		return nil, entity.ErrInternal
+	}
+
+	var next string
+	if page.Next != nil {
+		next = page.Next.Encode()
+	}
+	resp := &entity.OrderListResponse{NextCursor: next}
+	if cacheable {
+		resp.Orders = u.cacheOrders(key, page.Orders, next, ver)
+	} else {
+		resp.Orders = make([]*entity.OrderResponse, 0, len(page.Orders))
+		for _, o := range page.Orders {
+			resp.Orders = append(resp.Orders, mapOrderToResponse(o))
+		}
+	}
+
+	logger.Info("succsessfuly found orders", zap.Int("found", len(resp.Orders)))
+
+	return resp, nil
+}
+
+// cachedRefs собирает ответ из вторичного и основного кэшей.
+// Если хотя бы один заказ вытеснен из основного кэша, это промах.
+func (u *UsecaseLayer) cachedRefs(key string) ([]*entity.OrderResponse, string, bool) {
+	if u.refs == nil {
+		return nil, "", false
+	}
+	refs := u.refs.Get(key)
+	if refs == nil {
+		return nil, "", false
+	}
+
+	orders := make([]*entity.OrderResponse, 0, len(refs.UIDs))
+	for _, uid := range refs.UIDs {
+		o := u.cache.Get(uid)
+		if o == nil {
+			return nil, "", false
+		}
+		orders = append(orders, o)
+	}
+
+	return orders, refs.NextCursor, true
+}
+
+// cacheOrders кладёт найденные заказы в основной кэш, а их order_uid — во вторичный.
+// Пустой результат не кэшируется: заказ могла сохранить другая реплика,
+// и invalidateRefs этой реплики о нём не узнает. ver — общая версия до запроса
+// в бд: если за время запроса какой-то заказ изменился, положенные заказы
+// убираются из основного кэша, список order_uid от этого не устаревает.
+func (u *UsecaseLayer) cacheOrders(key string, found []*entity.OrderInfo, next string, ver uint64) []*entity.OrderResponse {
+	orders := make([]*entity.OrderResponse, 0, len(found))
+	uids := make([]string, 0, len(found))
+	for _, o := range found {
+		dto := mapOrderToResponse(o)
+		u.cache.Put(dto.OrderUID, dto)
+		orders = append(orders, dto)
+		uids = append(uids, dto.OrderUID)
+	}
+	if u.staleVer.all() != ver {
+		for _, uid := range uids {
+			u.cache.Delete(uid)
+		}
+	}
+	if u.refs != nil && len(uids) > 0 {
+		u.refs.Put(key, &entity.OrderRefs{UIDs: uids, NextCursor: next})
+	}
+
+	return orders
+}
+
+// invalidateRefs сбрасывает записи вторичного кэша, в которые мог бы попасть новый заказ.
+func (u *UsecaseLayer) invalidateRefs(order *entity.OrderInfo) {
+	if u.refs == nil {
+		return
+	}
+	u.refs.Delete(refsTrackPrefix + order.TrackNumber)
+	u.refs.Delete(refsTransactionPrefix + order.Payment.Transaction)
+	u.refs.Delete(refsCustomerPrefix + order.CustomerID)
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeRepo struct {
+	RepoLayer
+	orders []*entity.OrderInfo
+	calls  int
+}
+
+func (f *fakeRepo) GetOrdersByTrackNumber(_ context.Context, track string, _ int) ([]*entity.OrderInfo, error) {
+	f.calls++
+	var out []*entity.OrderInfo
+	for _, o := range f.orders {
+		if o.TrackNumber == track {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) SetOrder(_ context.Context, o *entity.OrderInfo) error {
+	f.orders = append(f.orders, o)
+	return nil
+}
+
+// validOrder — заказ, проходящий бизнес-валидацию.
+func validOrder(uid string) *entity.OrderInfo {
+	return &entity.OrderInfo{
+		OrderUID:        uid,
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		ShardKey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+		Delivery: entity.DeliveryInfo{
+			Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+			Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+		},
+		Payment: entity.PaymentInfo{
+			Transaction: uid, Currency: "USD", Provider: "wbpay",
+			Amount: 1817, PaymentDT: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+		},
+		Items: []entity.ItemInfo{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Sale: 30, TotalPrice: 317, Name: "Mascaras"},
+		},
+	}
+}
+
+func newLookupUsecase(repo *fakeRepo) *UsecaseLayer {
+	return New(zap.NewNop(), repo,
+		lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil),
+		WithRefsCache(lru_cache.NewLruCache[string, *entity.OrderRefs](10, nil)),
+	)
+}
+
+func TestGetOrdersByTrackUsesRefsCache(t *testing.T) {
+	repo := &fakeRepo{orders: []*entity.OrderInfo{{OrderUID: "a", TrackNumber: "WBILMTESTTRACK"}}}
+	uc := newLookupUsecase(repo)
+
+	for range 3 {
+		resp, err := uc.GetOrdersByTrack(context.Background(), "WBILMTESTTRACK")
+		require.NoError(t, err)
+		require.Len(t, resp.Orders, 1)
+		require.Equal(t, "a", resp.Orders[0].OrderUID)
+	}
+	require.Equal(t, 1, repo.calls)
+}
+
+func TestGetOrdersByTrackDoesNotCacheEmptyResult(t *testing.T) {
+	repo := &fakeRepo{}
+	uc := newLookupUsecase(repo)
+
+	_, err := uc.GetOrdersByTrack(context.Background(), "WBILMTESTTRACK")
+	require.ErrorIs(t, err, entity.ErrorOrderNotFound)
+
+	// заказ сохранила другая реплика: локальный invalidateRefs не вызывался
+	repo.orders = append(repo.orders, validOrder("b"))
+
+	resp, err := uc.GetOrdersByTrack(context.Background(), "WBILMTESTTRACK")
+	require.NoError(t, err)
+	require.Len(t, resp.Orders, 1)
+	require.Equal(t, 2, repo.calls)
+}
+
+func TestGetOrdersByTrackInvalidatedOnAdd(t *testing.T) {
+	repo := &fakeRepo{orders: []*entity.OrderInfo{validOrder("a")}}
+	uc := newLookupUsecase(repo)
+
+	for range 2 {
+		resp, err := uc.GetOrdersByTrack(context.Background(), "WBILMTESTTRACK")
+		require.NoError(t, err)
+		require.Len(t, resp.Orders, 1)
+	}
+	require.Equal(t, 1, repo.calls)
+
+	require.NoError(t, uc.AddOrderInfo(context.Background(), validOrder("b")))
+
+	resp, err := uc.GetOrdersByTrack(context.Background(), "WBILMTESTTRACK")
+	require.NoError(t, err)
+	require.Len(t, resp.Orders, 2)
+	require.Equal(t, 2, repo.calls)
+}
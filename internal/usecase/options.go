@@ -0,0 +1,27 @@
+package usecase
+
+import "time"
+
+// Option -.
+type Option func(*UsecaseLayer)
+
+// WithRefsCache включает кэширование поиска по вторичным ключам.
+func WithRefsCache(cache OrderRefsCache) Option {
+	return func(u *UsecaseLayer) {
+		u.refs = cache
+	}
+}
+
+// WithNotFoundCache включает негативное кэширование ErrorOrderNotFound.
+func WithNotFoundCache(cache NotFoundCache) Option {
+	return func(u *UsecaseLayer) {
+		u.notFound = cache
+	}
+}
+
+// WithFetchTimeout задаёт таймаут общего запроса в бд при промахе кэша.
+func WithFetchTimeout(timeout time.Duration) Option {
+	return func(u *UsecaseLayer) {
+		u.fetchTimeout = timeout
+	}
+}
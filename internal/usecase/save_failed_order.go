@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+func (u *UsecaseLayer) SaveFailedOrder(ctx context.Context, failed *entity.FailedOrder) error {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "SaveFailedOrder"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	// 3) паркуем сообщение
+	if err := u.db.SetFailedOrder(ctx, failed); err != nil {
+		logger.Error("cant park failed order", zap.String("order_uid", failed.OrderUID), zap.Error(err))
+
+		return entity.ErrInternal
+	}
+
+	return nil
+}
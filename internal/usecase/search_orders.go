@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+func (u *UsecaseLayer) SearchOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderListResponse, error) {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "SearchOrders"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	// 3) проверяем фильтр
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = entity.DefaultPageLimit
+	case filter.Limit > entity.MaxPageLimit:
+		filter.Limit = entity.MaxPageLimit
+	}
+	if !filter.DateFrom.IsZero() && !filter.DateTo.IsZero() && !filter.DateFrom.Before(filter.DateTo) {
+		logger.Warn("invalid date range", zap.Time("date_from", filter.DateFrom), zap.Time("date_to", filter.DateTo))
+
+		return nil, entity.ErrInvalidInput
+	}
+
+	// 4) ищем в бд
+	page, err := u.db.SearchOrders(ctx, filter)
+	if err != nil {
+		logger.Error("search failed", zap.Error(err))
+
+		return nil, entity.ErrInternal
+	}
+
+	resp := &entity.OrderListResponse{
+		Orders: make([]*entity.OrderResponse, 0, len(page.Orders)),
+	}
+	for _, o := range page.Orders {
+		resp.Orders = append(resp.Orders, mapOrderToResponse(o))
+	}
+	if page.Next != nil {
+		resp.NextCursor = page.Next.Encode()
+	}
+
+	logger.Info("search completed", zap.Int("found", len(resp.Orders)))
+
+	return resp, nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"go.uber.org/zap"
+)
+
+// UpdateOrderStatus переводит заказ (или позицию, если задан ChrtID) в новый статус.
+// Повтор уже применённого перехода не считается ошибкой: возвращается изменение с From == To.
+func (u *UsecaseLayer) UpdateOrderStatus(ctx context.Context, upd *entity.StatusUpdate, source string) (*entity.StatusChange, error) {
+	// 1) забираем request_id
+	reqID, _ := ctx.Value(entity.RequestIDKey{}).(string)
+
+	// 2) оборачиваем логгер
+	logger := u.log.With(zap.String("func", "UpdateOrderStatus"))
+	if reqID != "" {
+		logger = logger.With(zap.String("request_id", reqID))
+	}
+
+	// 3) валидируем запрос
+	if upd == nil || upd.OrderUID == "" || upd.ChrtID < 0 || !upd.Status.Valid() {
+		logger.Warn("invalid status update", zap.Any("update", upd))
+
+		return nil, entity.ErrInvalidInput
+	}
+	logger = logger.With(zap.String("order_uid", upd.OrderUID), zap.Int64("chrt_id", upd.ChrtID))
+
+	// 4) проверяем переход по текущему статусу
+	current, err := u.db.GetOrderStatus(ctx, upd.OrderUID, upd.ChrtID)
+	if err != nil {
+		if errors.Is(err, entity.ErrorOrderNotFound) {
+			logger.Info("order not found")
+
+			return nil, entity.ErrorOrderNotFound
+		}
+		logger.Error("get status failed", zap.Error(err))
+
+		return nil, entity.ErrInternal
+	}
+
+	change := &entity.StatusChange{
+		OrderUID:  upd.OrderUID,
+		ChrtID:    upd.ChrtID,
+		From:      current,
+		To:        upd.Status,
+		Reason:    upd.Reason,
+		Source:    source,
+		RequestID: reqID,
+	}
+	if current == upd.Status {
+		logger.Info("status already set", zap.String("status", string(current)))
+
+		return change, nil
+	}
+	if !current.CanTransitionTo(upd.Status) {
+		logger.Warn("transition not allowed",
+			zap.String("from", string(current)),
+			zap.String("to", string(upd.Status)),
+		)
+
+		return nil, entity.ErrInvalidTransition
+	}
+
+	// 5) применяем
+	if err := u.db.UpdateOrderStatus(ctx, change); err != nil {
+		if errors.Is(err, entity.ErrStatusConflict) {
+			return nil, entity.ErrStatusConflict
+		}
+		logger.Error("update status failed", zap.Error(err))
+
+		return nil, entity.ErrInternal
+	}
+
+	// 6) сбрасываем заказ в кэше, включая общий уровень
+	u.evictStatusFromCache(change)
+
+	logger.Info("status updated",
+		zap.String("from", string(change.From)),
+		zap.String("to", string(change.To)),
+		zap.String("source", source),
+	)
+
+	return change, nil
+}
+
+// evictStatusFromCache удаляет заказ из кэша после смены статуса. Ответ не
+// переписывается из локальной копии: у реплики, которая не держит заказ в LRU,
+// её просто нет, и общий уровень (Redis) остался бы со старым статусом.
+// Следующее чтение возьмёт заказ из БД и заново заполнит оба уровня; чтение,
+// начатое до смены статуса, увидит новую версию и не вернёт старый ответ в кэш.
+func (u *UsecaseLayer) evictStatusFromCache(change *entity.StatusChange) {
+	u.staleVer.bump(change.OrderUID)
+	u.cache.Delete(change.OrderUID)
+}
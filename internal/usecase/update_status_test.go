@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
+	"github.com/RozmiDan/wb_tech_testtask/internal/repo/rediscache"
+	lru_cache "github.com/RozmiDan/wb_tech_testtask/pkg/cache"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type statusRepo struct {
+	RepoLayer
+	status  entity.OrderStatus
+	applied []*entity.StatusChange
+}
+
+func (r *statusRepo) GetOrderStatus(context.Context, string, int64) (entity.OrderStatus, error) {
+	return r.status, nil
+}
+
+func (r *statusRepo) UpdateOrderStatus(_ context.Context, change *entity.StatusChange) error {
+	r.status = change.To
+	r.applied = append(r.applied, change)
+	return nil
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	repo := &statusRepo{status: entity.StatusCreated}
+	cache := lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil)
+	cached := &entity.OrderResponse{OrderUID: "a", Status: entity.StatusCreated}
+	cache.Put("a", cached)
+	uc := New(zap.NewNop(), repo, cache)
+
+	change, err := uc.UpdateOrderStatus(context.Background(), &entity.StatusUpdate{OrderUID: "a", Status: entity.StatusPaid}, entity.StatusSourceHTTP)
+	require.NoError(t, err)
+	require.Equal(t, entity.StatusCreated, change.From)
+	require.Equal(t, entity.StatusPaid, change.To)
+	require.Nil(t, cache.Get("a"), "stale order is evicted, next read goes to the db")
+	require.Equal(t, entity.StatusCreated, cached.Status, "cached response is not mutated")
+
+	// повтор того же перехода — не ошибка и не новая запись в истории
+	_, err = uc.UpdateOrderStatus(context.Background(), &entity.StatusUpdate{OrderUID: "a", Status: entity.StatusPaid}, entity.StatusSourceKafka)
+	require.NoError(t, err)
+	require.Len(t, repo.applied, 1)
+
+	_, err = uc.UpdateOrderStatus(context.Background(), &entity.StatusUpdate{OrderUID: "a", Status: entity.StatusDelivered}, entity.StatusSourceHTTP)
+	require.ErrorIs(t, err, entity.ErrInvalidTransition)
+
+	_, err = uc.UpdateOrderStatus(context.Background(), &entity.StatusUpdate{OrderUID: "a", Status: "lost"}, entity.StatusSourceHTTP)
+	require.ErrorIs(t, err, entity.ErrInvalidInput)
+}
+
+// racingStatusRepo отдаёт заказ со статусом, прочитанным до release: так
+// чтение из бд начинается до смены статуса, а заканчивается после неё.
+type racingStatusRepo struct {
+	statusRepo
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (r *racingStatusRepo) GetOrderByUID(_ context.Context, orderUID string) (*entity.OrderInfo, error) {
+	read := r.status
+	close(r.reading)
+	<-r.release
+	return &entity.OrderInfo{OrderUID: orderUID, Status: read}, nil
+}
+
+func TestUpdateOrderStatusDuringReadKeepsCacheFresh(t *testing.T) {
+	repo := &racingStatusRepo{
+		statusRepo: statusRepo{status: entity.StatusCreated},
+		reading:    make(chan struct{}),
+		release:    make(chan struct{}),
+	}
+	cache := lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil)
+	uc := New(zap.NewNop(), repo, cache)
+
+	read := make(chan *entity.OrderResponse, 1)
+	go func() {
+		o, _ := uc.GetOrderInfo(context.Background(), "a")
+		read <- o
+	}()
+	<-repo.reading
+
+	_, err := uc.UpdateOrderStatus(context.Background(), &entity.StatusUpdate{OrderUID: "a", Status: entity.StatusPaid}, entity.StatusSourceHTTP)
+	require.NoError(t, err)
+	close(repo.release)
+
+	o := <-read
+	require.NotNil(t, o)
+	require.Equal(t, entity.StatusCreated, o.Status, "the in-flight read still answers its caller")
+	require.Nil(t, cache.Get("a"), "but its old status is not left in the cache")
+}
+
+func TestUpdateOrderStatusClearsSharedCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	replica := func() *rediscache.Layered {
+		return rediscache.New(lru_cache.NewLruCache[string, *entity.OrderResponse](10, nil), rdb, zap.NewNop())
+	}
+
+	// реплика A закэшировала заказ, статус меняет реплика B, у которой его нет в LRU
+	a, b := replica(), replica()
+	a.Put("a", &entity.OrderResponse{OrderUID: "a", Status: entity.StatusCreated})
+
+	uc := New(zap.NewNop(), &statusRepo{status: entity.StatusCreated}, b)
+	_, err := uc.UpdateOrderStatus(context.Background(), &entity.StatusUpdate{OrderUID: "a", Status: entity.StatusPaid}, entity.StatusSourceHTTP)
+	require.NoError(t, err)
+	require.Empty(t, mr.Keys(), "shared tier no longer serves the old status")
+	require.Nil(t, b.Get("a"))
+}
@@ -2,32 +2,86 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/RozmiDan/wb_tech_testtask/internal/entity"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
+// defaultFetchTimeout ограничивает общий запрос в бд при промахе кэша,
+// который не привязан к контексту одного клиента.
+const defaultFetchTimeout = 5 * time.Second
+
 type RepoLayer interface {
 	GetOrderByUID(ctx context.Context, orderUID string) (*entity.OrderInfo, error)
 	SetOrder(ctx context.Context, order *entity.OrderInfo) error
+	SetOrders(ctx context.Context, orders []*entity.OrderInfo) ([]entity.OrderResult, error)
 	GetLatestOrders(ctx context.Context, limit int) ([]*entity.OrderInfo, error)
+	GetOrdersByTrackNumber(ctx context.Context, trackNumber string, limit int) ([]*entity.OrderInfo, error)
+	GetOrderByTransaction(ctx context.Context, transaction string) (*entity.OrderInfo, error)
+	SearchOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderPage, error)
+	ExportOrders(ctx context.Context, filter entity.OrderFilter, fn func(*entity.OrderInfo) error) error
+	GetOrderStatus(ctx context.Context, orderUID string, chrtID int64) (entity.OrderStatus, error)
+	UpdateOrderStatus(ctx context.Context, change *entity.StatusChange) error
+	SetFailedOrder(ctx context.Context, failed *entity.FailedOrder) error
 }
 
 type OrderCache interface {
 	Put(key string, val *entity.OrderResponse)
 	Get(key string) *entity.OrderResponse
+	Peek(key string) (*entity.OrderResponse, bool)
+	Delete(key string) bool
+	Purge()
+}
+
+// OrderRefsCache — кэш поиска по вторичным ключам: ключ -> order_uid найденных заказов.
+type OrderRefsCache interface {
+	Put(key string, val *entity.OrderRefs)
+	Get(key string) *entity.OrderRefs
+	Delete(key string) bool
+	Purge()
+}
+
+// NotFoundCache — негативный кэш: order_uid, которых недавно не нашлось в бд.
+// Записи должны жить недолго (TTL задаётся реализацией).
+type NotFoundCache interface {
+	Put(key string, val struct{})
+	Contains(key string) bool
+	Delete(key string) bool
+	Purge()
 }
 
 type UsecaseLayer struct {
-	log   *zap.Logger
-	db    RepoLayer
-	cache OrderCache
+	log      *zap.Logger
+	db       RepoLayer
+	cache    OrderCache
+	refs     OrderRefsCache
+	notFound NotFoundCache
+
+	// версии защищают кэши от результатов чтения, устаревших за время
+	// запроса в бд: staleVer растёт при смене статуса, missVer — при появлении заказа
+	staleVer *versions
+	missVer  *versions
+
+	// одновременные промахи по одному order_uid делят один запрос в бд
+	inflight     singleflight.Group
+	fetchTimeout time.Duration
 }
 
-func New(logger *zap.Logger, dbLayer RepoLayer, cache OrderCache) *UsecaseLayer {
-	return &UsecaseLayer{
+func New(logger *zap.Logger, dbLayer RepoLayer, cache OrderCache, opts ...Option) *UsecaseLayer {
+	u := &UsecaseLayer{
 		log:   logger.With(zap.String("layer", "Usecase")),
 		db:    dbLayer,
 		cache: cache,
+
+		staleVer:     newVersions(),
+		missVer:      newVersions(),
+		fetchTimeout: defaultFetchTimeout,
 	}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
 }
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"hash/maphash"
+	"sync/atomic"
+)
+
+// versionStripes — число счётчиков версий; order_uid с одним счётчиком
+// изредка лишь теряют заполнение кэша, но не получают устаревших данных.
+const versionStripes = 256
+
+// versions считает изменения заказов, чтобы чтение из бд, начатое до
+// изменения, не вернуло в кэш устаревший результат.
+//
+// Читающий запоминает версию до запроса в бд, кладёт результат в кэш и
+// перечитывает версию: если она изменилась, удаляет только что положенное.
+// Изменяющий сначала поднимает версию, затем удаляет запись. При любом
+// порядке шагов устаревшая запись в кэше не остаётся.
+type versions struct {
+	seed    maphash.Seed
+	epoch   atomic.Uint64
+	stripes [versionStripes]atomic.Uint64
+}
+
+func newVersions() *versions {
+	return &versions{seed: maphash.MakeSeed()}
+}
+
+// of возвращает версию order_uid.
+func (v *versions) of(orderUID string) uint64 {
+	return v.stripe(orderUID).Load()
+}
+
+// all возвращает общую версию для запросов, чьи order_uid заранее неизвестны.
+func (v *versions) all() uint64 {
+	return v.epoch.Load()
+}
+
+// bump отмечает изменение заказа.
+func (v *versions) bump(orderUID string) {
+	v.stripe(orderUID).Add(1)
+	v.epoch.Add(1)
+}
+
+func (v *versions) stripe(orderUID string) *atomic.Uint64 {
+	return &v.stripes[maphash.String(v.seed, orderUID)%versionStripes]
+}
@@ -2,6 +2,8 @@ package lru_cache
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"iter"
 
@@ -11,13 +13,96 @@ import (
 type LRU[K comparable, V any] interface {
 	Put(key K, val V)
 	Get(key K) V
+	Peek(key K) (V, bool)
+	Contains(key K) bool
+	Delete(key K) bool
+	Purge()
 	Size() int
 	All() iter.Seq2[K, V]
 }
 
 type Node[K comparable, V any] struct {
-	key   K
-	value V
+	key       K
+	value     V
+	cost      int64
+	expiresAt time.Time // нулевое значение — без TTL
+}
+
+// EvictReason — почему запись покинула кэш.
+type EvictReason int
+
+const (
+	EvictCapacity EvictReason = iota // вытеснена по LRU при переполнении
+	EvictDeleted                     // удалена через Delete
+	EvictPurged                      // удалена через Purge
+	EvictExpired                     // истёк TTL
+	EvictCost                        // вытеснена по LRU при превышении maxCost
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictDeleted:
+		return "deleted"
+	case EvictPurged:
+		return "purged"
+	case EvictExpired:
+		return "expired"
+	case EvictCost:
+		return "cost"
+	default:
+		return "unknown"
+	}
+}
+
+// OnEvictFunc вызывается для каждой покинувшей кэш записи.
+// Вызов происходит вне блокировки, поэтому из колбэка можно обращаться к кэшу.
+type OnEvictFunc[K comparable, V any] func(key K, val V, reason EvictReason)
+
+// Option -.
+type Option[K comparable, V any] func(*LruCache[K, V])
+
+// WithOnEvict задаёт колбэк на удаление записей.
+func WithOnEvict[K comparable, V any](fn OnEvictFunc[K, V]) Option[K, V] {
+	return func(c *LruCache[K, V]) {
+		c.onEvict = fn
+	}
+}
+
+// WithTTL задаёт время жизни записи с момента последнего Put.
+// Просроченные записи удаляются лениво при обращении к ним.
+func WithTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(c *LruCache[K, V]) {
+		c.ttl = ttl
+	}
+}
+
+// WithCleanupInterval запускает фоновое удаление просроченных записей.
+// Имеет смысл вместе с WithTTL; горутина останавливается через Close.
+func WithCleanupInterval[K comparable, V any](interval time.Duration) Option[K, V] {
+	return func(c *LruCache[K, V]) {
+		c.cleanupInterval = interval
+	}
+}
+
+// WithMaxCost ограничивает суммарную стоимость записей, посчитанную cost
+// (например, примерный размер значения в байтах). Запись дороже maxCost не сохраняется.
+func WithMaxCost[K comparable, V any](maxCost int64, cost func(V) int64) Option[K, V] {
+	return func(c *LruCache[K, V]) {
+		c.maxCost = maxCost
+		c.costFn = cost
+	}
+}
+
+// Stats — счётчики обращений к кэшу с момента создания.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Expired   uint64
+	Size      int
+	Cost      int64
 }
 
 type LruCache[K comparable, V any] struct {
@@ -25,26 +110,59 @@ type LruCache[K comparable, V any] struct {
 	mp           map[K]*linklist.Node[Node[K, V]]
 	defaultValue V
 	capacity     int
+	onEvict      OnEvictFunc[K, V]
 	mu           sync.RWMutex
+
+	ttl             time.Duration
+	cleanupInterval time.Duration
+	maxCost         int64
+	costFn          func(V) int64
+	totalCost       int64
+	now             func() time.Time
+	stop            chan struct{}
+	stopOnce        sync.Once
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	expired   atomic.Uint64
 }
 
-func NewLruCache[K comparable, V any](cap int, defVal V) *LruCache[K, V] {
+func NewLruCache[K comparable, V any](cap int, defVal V, opts ...Option[K, V]) *LruCache[K, V] {
 	if cap <= 0 {
 		panic("lru: capacity must be > 0")
 	}
-	return &LruCache[K, V]{
+	c := &LruCache[K, V]{
 		list:         linklist.NewList[Node[K, V]](),
 		mp:           make(map[K]*linklist.Node[Node[K, V]], cap),
 		defaultValue: defVal,
 		capacity:     cap,
+		now:          time.Now,
+		stop:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.ttl > 0 && c.cleanupInterval > 0 {
+		go c.cleanupLoop()
+	}
+	return c
+}
+
+// Close останавливает фоновую очистку. Кэш остаётся рабочим.
+func (lru *LruCache[K, V]) Close() {
+	lru.stopOnce.Do(func() { close(lru.stop) })
 }
 
 func (lru *LruCache[K, V]) All() iter.Seq2[K, V] {
 	return func(yield func(K, V) bool) {
 		lru.mu.RLock()
 		defer lru.mu.RUnlock()
+		now := lru.now()
 		for it := range lru.list.All() {
+			if lru.expiredAt(it, now) {
+				continue
+			}
 			if !yield(it.key, it.value) {
 				return
 			}
@@ -53,41 +171,230 @@ func (lru *LruCache[K, V]) All() iter.Seq2[K, V] {
 }
 
 func (lru *LruCache[K, V]) Put(key K, val V) {
+	entry := Node[K, V]{key: key, value: val}
+	if lru.ttl > 0 {
+		entry.expiresAt = lru.now().Add(lru.ttl)
+	}
+	if lru.costFn != nil {
+		entry.cost = lru.costFn(val)
+	}
+
 	lru.mu.Lock()
-	defer lru.mu.Unlock()
+	var removed []evicted[K, V]
 
-	if n, ok := lru.mp[key]; ok {
-		lru.list.PutNewValue(n, Node[K, V]{key: key, value: val})
-		lru.list.MoveToFront(n)
+	n, exists := lru.mp[key]
+	switch {
+	case lru.maxCost > 0 && entry.cost > lru.maxCost:
+		// не помещается даже в пустой кэш: старое значение тоже убираем, чтобы не отдавать устаревшее
+		if exists {
+			removed = append(removed, lru.evictNode(n, EvictCost))
+		}
+		lru.mu.Unlock()
+		lru.notifyAll(removed)
 
 		return
+	case exists:
+		lru.totalCost += entry.cost - n.GetData().cost
+		lru.list.PutNewValue(n, entry)
+		lru.list.MoveToFront(n)
+	default:
+		if lru.list.Size() >= lru.capacity {
+			if tail := lru.list.Back(); tail != nil {
+				removed = append(removed, lru.evictNode(tail, EvictCapacity))
+			}
+		}
+		lru.list.PushFront(entry)
+		lru.mp[key] = lru.list.Front()
+		lru.totalCost += entry.cost
 	}
 
-	if lru.list.Size() >= lru.capacity {
+	for lru.maxCost > 0 && lru.totalCost > lru.maxCost {
 		tail := lru.list.Back()
-		if tail != nil {
-			evicted := lru.list.Remove(tail)
-			delete(lru.mp, evicted.key)
+		if tail == nil || tail == lru.mp[key] {
+			break
 		}
+		removed = append(removed, lru.evictNode(tail, EvictCost))
 	}
+	lru.mu.Unlock()
 
-	lru.list.PushFront(Node[K, V]{key: key, value: val})
-	lru.mp[key] = lru.list.Front()
+	lru.notifyAll(removed)
 }
 
 func (lru *LruCache[K, V]) Get(key K) V {
 	lru.mu.Lock()
+
+	n, ok := lru.mp[key]
+	if ok && lru.expiredAt(n.GetData(), lru.now()) {
+		removed := lru.removeNode(n)
+		lru.expired.Add(1)
+		lru.misses.Add(1)
+		lru.mu.Unlock()
+
+		lru.notify(removed, EvictExpired)
+		return lru.defaultValue
+	}
 	defer lru.mu.Unlock()
 
-	if n, ok := lru.mp[key]; ok {
+	if ok {
 		lru.list.MoveToFront(n)
+		lru.hits.Add(1)
 		return n.GetData().value
 	}
+	lru.misses.Add(1)
 	return lru.defaultValue
 }
 
+// Peek возвращает значение, не меняя порядок вытеснения и счётчики попаданий.
+func (lru *LruCache[K, V]) Peek(key K) (V, bool) {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+
+	if n, ok := lru.mp[key]; ok && !lru.expiredAt(n.GetData(), lru.now()) {
+		return n.GetData().value, true
+	}
+	return lru.defaultValue, false
+}
+
+// Contains сообщает, есть ли ключ в кэше, не меняя порядок вытеснения.
+func (lru *LruCache[K, V]) Contains(key K) bool {
+	lru.mu.RLock()
+	defer lru.mu.RUnlock()
+
+	n, ok := lru.mp[key]
+	return ok && !lru.expiredAt(n.GetData(), lru.now())
+}
+
+// Delete удаляет ключ и сообщает, был ли он в кэше.
+func (lru *LruCache[K, V]) Delete(key K) bool {
+	lru.mu.Lock()
+	n, ok := lru.mp[key]
+	if !ok {
+		lru.mu.Unlock()
+		return false
+	}
+	removed := lru.removeNode(n)
+	lru.mu.Unlock()
+
+	lru.notify(removed, EvictDeleted)
+	return true
+}
+
+// Purge очищает кэш. Счётчики статистики не сбрасываются.
+func (lru *LruCache[K, V]) Purge() {
+	lru.mu.Lock()
+	var removed []Node[K, V]
+	if lru.onEvict != nil {
+		removed = make([]Node[K, V], 0, lru.list.Size())
+		for it := range lru.list.All() {
+			removed = append(removed, it)
+		}
+	}
+	lru.list = linklist.NewList[Node[K, V]]()
+	lru.mp = make(map[K]*linklist.Node[Node[K, V]], lru.capacity)
+	lru.totalCost = 0
+	lru.mu.Unlock()
+
+	for _, n := range removed {
+		lru.notify(n, EvictPurged)
+	}
+}
+
 func (lru *LruCache[K, V]) Size() int {
 	lru.mu.RLock()
 	defer lru.mu.RUnlock()
 	return lru.list.Size()
 }
+
+func (lru *LruCache[K, V]) Stats() Stats {
+	lru.mu.RLock()
+	size, cost := lru.list.Size(), lru.totalCost
+	lru.mu.RUnlock()
+
+	return Stats{
+		Hits:      lru.hits.Load(),
+		Misses:    lru.misses.Load(),
+		Evictions: lru.evictions.Load(),
+		Expired:   lru.expired.Load(),
+		Size:      size,
+		Cost:      cost,
+	}
+}
+
+// DeleteExpired удаляет все просроченные записи и возвращает их число.
+func (lru *LruCache[K, V]) DeleteExpired() int {
+	if lru.ttl <= 0 {
+		return 0
+	}
+
+	lru.mu.Lock()
+	now := lru.now()
+	var removed []evicted[K, V]
+	// TTL отсчитывается от Put, а порядок списка — по последнему доступу,
+	// поэтому просроченные записи могут быть где угодно: проходим весь список
+	for _, n := range lru.mp {
+		if lru.expiredAt(n.GetData(), now) {
+			removed = append(removed, evicted[K, V]{lru.removeNode(n), EvictExpired})
+		}
+	}
+	lru.expired.Add(uint64(len(removed)))
+	lru.mu.Unlock()
+
+	lru.notifyAll(removed)
+	return len(removed)
+}
+
+func (lru *LruCache[K, V]) cleanupLoop() {
+	ticker := time.NewTicker(lru.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lru.stop:
+			return
+		case <-ticker.C:
+			lru.DeleteExpired()
+		}
+	}
+}
+
+type evicted[K comparable, V any] struct {
+	node   Node[K, V]
+	reason EvictReason
+}
+
+func (lru *LruCache[K, V]) expiredAt(n Node[K, V], now time.Time) bool {
+	return !n.expiresAt.IsZero() && !now.Before(n.expiresAt)
+}
+
+// removeNode убирает узел из списка и мапы. Вызывается под mu.
+func (lru *LruCache[K, V]) removeNode(n *linklist.Node[Node[K, V]]) Node[K, V] {
+	removed := lru.list.Remove(n)
+	delete(lru.mp, removed.key)
+	lru.totalCost -= removed.cost
+	return removed
+}
+
+// evictNode вытесняет запись: хвост списка или запись, которую заменяет слишком
+// дорогое значение. Просроченная запись считается истёкшей, а не вытесненной.
+// Вызывается под mu.
+func (lru *LruCache[K, V]) evictNode(n *linklist.Node[Node[K, V]], reason EvictReason) evicted[K, V] {
+	if lru.expiredAt(n.GetData(), lru.now()) {
+		reason = EvictExpired
+		lru.expired.Add(1)
+	} else {
+		lru.evictions.Add(1)
+	}
+	return evicted[K, V]{lru.removeNode(n), reason}
+}
+
+func (lru *LruCache[K, V]) notify(n Node[K, V], reason EvictReason) {
+	if lru.onEvict != nil {
+		lru.onEvict(n.key, n.value, reason)
+	}
+}
+
+func (lru *LruCache[K, V]) notifyAll(removed []evicted[K, V]) {
+	for _, e := range removed {
+		lru.notify(e.node, e.reason)
+	}
+}
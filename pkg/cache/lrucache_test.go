@@ -198,3 +198,215 @@ func TestConcurrentAllVsWrites(t *testing.T) {
 
 	mustLE(t, c.Size(), 64, "size must not exceed capacity after concurrent use")
 }
+
+func TestStatsCountHitsMissesEvictions(t *testing.T) {
+	t.Parallel()
+
+	c := NewLruCache[string, int](2, -1)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3) // вытесняет a
+
+	_ = c.Get("b")
+	_ = c.Get("c")
+	_ = c.Get("a")
+
+	s := c.Stats()
+	mustEqual(t, s.Hits, uint64(2), "hits")
+	mustEqual(t, s.Misses, uint64(1), "misses")
+	mustEqual(t, s.Evictions, uint64(1), "evictions")
+	mustEqual(t, s.Size, 2, "size")
+}
+
+func TestPeekAndContainsDoNotTouchRecency(t *testing.T) {
+	t.Parallel()
+
+	c := NewLruCache[string, int](2, -1)
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	v, ok := c.Peek("a")
+	mustEqual(t, ok, true, "peek a found")
+	mustEqual(t, v, 1, "peek a value")
+	mustEqual(t, c.Contains("a"), true, "contains a")
+	_, ok = c.Peek("missing")
+	mustEqual(t, ok, false, "peek missing")
+
+	// a остаётся LRU, несмотря на Peek/Contains
+	c.Put("c", 3)
+	mustEqual(t, c.Contains("a"), false, "a evicted")
+	mustEqual(t, c.Contains("b"), true, "b survives")
+
+	s := c.Stats()
+	mustEqual(t, s.Hits, uint64(0), "peek is not a hit")
+	mustEqual(t, s.Misses, uint64(0), "peek is not a miss")
+}
+
+func TestDeleteAndPurge(t *testing.T) {
+	t.Parallel()
+
+	c := NewLruCache[string, int](3, -1)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+
+	mustEqual(t, c.Delete("b"), true, "delete existing")
+	mustEqual(t, c.Delete("b"), false, "delete twice")
+	mustEqual(t, c.Size(), 2, "size after delete")
+	mustEqual(t, c.Get("b"), -1, "deleted key is gone")
+
+	// освободившееся место занимается без вытеснения
+	c.Put("d", 4)
+	mustEqual(t, c.Get("a"), 1, "a survives")
+	mustEqual(t, c.Stats().Evictions, uint64(0), "no capacity evictions")
+
+	c.Purge()
+	mustEqual(t, c.Size(), 0, "size after purge")
+	mustEqual(t, c.Contains("a"), false, "purged")
+
+	var keys []string
+	for k := range c.All() {
+		keys = append(keys, k)
+	}
+	mustEqual(t, len(keys), 0, "iteration after purge")
+
+	c.Put("e", 5)
+	mustEqual(t, c.Get("e"), 5, "usable after purge")
+}
+
+func TestOnEvictReasons(t *testing.T) {
+	t.Parallel()
+
+	evicted := map[string]EvictReason{}
+	var c *LruCache[string, int]
+	c = NewLruCache(2, -1, WithOnEvict(func(k string, _ int, reason EvictReason) {
+		evicted[k] = reason
+		// колбэк вызывается без блокировки
+		_ = c.Contains(k)
+	}))
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3) // вытесняет a
+	c.Delete("b")
+	c.Purge() // удаляет c
+
+	mustEqual(t, len(evicted), 3, "all removals reported")
+	mustEqual(t, evicted["a"], EvictCapacity, "a reason")
+	mustEqual(t, evicted["b"], EvictDeleted, "b reason")
+	mustEqual(t, evicted["c"], EvictPurged, "c reason")
+}
+
+// fakeClock — управляемое время для проверок TTL.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestTTLExpiresLazily(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var reasons []EvictReason
+	c := NewLruCache(3, -1,
+		WithTTL[string, int](time.Minute),
+		WithOnEvict(func(_ string, _ int, r EvictReason) { reasons = append(reasons, r) }),
+	)
+	c.now = clock.Now
+
+	c.Put("a", 1)
+	clock.Advance(30 * time.Second)
+	c.Put("b", 2)
+	mustEqual(t, c.Get("a"), 1, "a alive before ttl")
+
+	// Get не продлевает TTL: он отсчитывается от Put
+	clock.Advance(30 * time.Second)
+	mustEqual(t, c.Contains("a"), false, "contains hides expired a")
+	_, ok := c.Peek("a")
+	mustEqual(t, ok, false, "peek hides expired a")
+	mustEqual(t, c.Get("a"), -1, "a expired")
+	mustEqual(t, c.Get("b"), 2, "b alive")
+	mustEqual(t, c.Size(), 1, "expired a removed on Get")
+
+	s := c.Stats()
+	mustEqual(t, s.Expired, uint64(1), "expired counter")
+	mustEqual(t, s.Evictions, uint64(0), "expiry is not an eviction")
+	mustEqual(t, len(reasons), 1, "one removal reported")
+	mustEqual(t, reasons[0], EvictExpired, "reason expired")
+}
+
+func TestDeleteExpiredAndBackgroundCleanup(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	c := NewLruCache(4, -1, WithTTL[string, int](time.Minute))
+	c.now = clock.Now
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	clock.Advance(30 * time.Second)
+	c.Put("c", 3)
+	_ = c.Get("a") // a становится MRU, но остаётся просроченным раньше c
+
+	clock.Advance(45 * time.Second)
+	mustEqual(t, c.DeleteExpired(), 2, "a and b expired")
+	mustEqual(t, c.Size(), 1, "only c left")
+
+	bg := NewLruCache(4, -1,
+		WithTTL[string, int](time.Millisecond),
+		WithCleanupInterval[string, int](time.Millisecond),
+	)
+	defer bg.Close()
+	bg.Put("x", 1)
+
+	deadline := time.Now().Add(time.Second)
+	for bg.Size() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	mustEqual(t, bg.Size(), 0, "background cleanup removed x")
+}
+
+func TestMaxCostEviction(t *testing.T) {
+	t.Parallel()
+
+	var reasons []EvictReason
+	c := NewLruCache(10, "",
+		WithMaxCost[string, string](10, func(v string) int64 { return int64(len(v)) }),
+		WithOnEvict(func(_ string, _ string, r EvictReason) { reasons = append(reasons, r) }),
+	)
+
+	c.Put("a", "aaaa")
+	c.Put("b", "bbbb")
+	mustEqual(t, c.Stats().Cost, int64(8), "cost of a and b")
+
+	c.Put("c", "cccc") // 12 > 10 -> вытесняется LRU a
+	mustEqual(t, c.Contains("a"), false, "a evicted by cost")
+	mustEqual(t, c.Stats().Cost, int64(8), "cost after eviction")
+
+	// обновление меняет стоимость записи
+	c.Put("b", "bb")
+	mustEqual(t, c.Stats().Cost, int64(6), "cost after shrinking b")
+
+	// запись дороже maxCost не сохраняется и убирает старое значение
+	c.Put("c", "ccccccccccc")
+	mustEqual(t, c.Contains("c"), false, "oversized value rejected")
+	mustEqual(t, c.Get("b"), "bb", "b untouched")
+	mustEqual(t, c.Stats().Cost, int64(2), "cost after rejecting c")
+
+	mustEqual(t, len(reasons), 2, "removals reported")
+	mustEqual(t, reasons[0], EvictCost, "a reason")
+	mustEqual(t, reasons[1], EvictCost, "c reason")
+	mustEqual(t, c.Stats().Evictions, uint64(2), "both removals counted as evictions")
+}
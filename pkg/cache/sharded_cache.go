@@ -0,0 +1,162 @@
+package lru_cache
+
+import (
+	"hash/maphash"
+	"iter"
+)
+
+// ShardedCache — набор независимых LruCache, между которыми ключи распределяются
+// по хэшу. Блокировка берётся только на шард ключа, поэтому параллельные Get
+// по разным ключам не сериализуются на одном мьютексе.
+// Порядок вытеснения соблюдается внутри шарда, а не глобально.
+type ShardedCache[K comparable, V any] struct {
+	shards []*LruCache[K, V]
+	seed   maphash.Seed
+}
+
+// NewShardedCache создаёт кэш из shards шардов общей ёмкостью cap.
+// Ёмкость и WithMaxCost делятся между шардами поровну, остаток от деления
+// достаётся первым шардам, так что суммарно лимиты совпадают с заданными.
+// Остальные опции применяются к каждому шарду.
+//
+// Каждый шард ограничен своей долей maxCost, поэтому запись дороже
+// maxCost/shards не сохраняется, даже если помещается в общий лимит
+// (см. MaxEntryCost).
+func NewShardedCache[K comparable, V any](cap, shards int, defVal V, opts ...Option[K, V]) *ShardedCache[K, V] {
+	if cap <= 0 {
+		panic("lru: capacity must be > 0")
+	}
+	shards = max(min(shards, cap), 1)
+
+	c := &ShardedCache[K, V]{
+		shards: make([]*LruCache[K, V], shards),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range c.shards {
+		s := NewLruCache(share(cap, shards, i), defVal, opts...)
+		if s.maxCost > 0 {
+			if s.maxCost < int64(shards) {
+				panic("lru: max cost must be >= number of shards")
+			}
+			s.maxCost = int64(share(int(s.maxCost), shards, i))
+		}
+		c.shards[i] = s
+	}
+	return c
+}
+
+// share — доля total, приходящаяся на шард i из n.
+func share(total, n, i int) int {
+	part := total / n
+	if i < total%n {
+		part++
+	}
+	return part
+}
+
+// MaxEntryCost возвращает стоимость самой дорогой записи, которую кэш
+// ещё сохранит (доля maxCost наименьшего шарда); 0 — без ограничения.
+func (c *ShardedCache[K, V]) MaxEntryCost() int64 {
+	return c.shards[len(c.shards)-1].maxCost
+}
+
+func (c *ShardedCache[K, V]) shard(key K) *LruCache[K, V] {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+	return c.shards[maphash.Comparable(c.seed, key)%uint64(len(c.shards))]
+}
+
+func (c *ShardedCache[K, V]) Put(key K, val V) { c.shard(key).Put(key, val) }
+
+func (c *ShardedCache[K, V]) Get(key K) V { return c.shard(key).Get(key) }
+
+func (c *ShardedCache[K, V]) Peek(key K) (V, bool) { return c.shard(key).Peek(key) }
+
+func (c *ShardedCache[K, V]) Contains(key K) bool { return c.shard(key).Contains(key) }
+
+func (c *ShardedCache[K, V]) Delete(key K) bool { return c.shard(key).Delete(key) }
+
+func (c *ShardedCache[K, V]) Purge() {
+	for _, s := range c.shards {
+		s.Purge()
+	}
+}
+
+func (c *ShardedCache[K, V]) Size() int {
+	size := 0
+	for _, s := range c.shards {
+		size += s.Size()
+	}
+	return size
+}
+
+// All обходит записи от недавно использованных к давним, чередуя шарды:
+// сначала самые свежие записи каждого шарда, затем вторые и т.д. Ключи
+// распределены по шардам равномерно, поэтому такой порядок приближает
+// глобальный LRU, и префикс обхода (например, снимок, восстановленный в кэш
+// меньшего размера) содержит самые горячие записи всех шардов, а не одного.
+// Записи шардов копируются до обхода, блокировки во время yield не держатся.
+func (c *ShardedCache[K, V]) All() iter.Seq2[K, V] {
+	if len(c.shards) == 1 {
+		return c.shards[0].All()
+	}
+	type entry struct {
+		key K
+		val V
+	}
+	return func(yield func(K, V) bool) {
+		ranked := make([][]entry, len(c.shards))
+		for i, s := range c.shards {
+			for k, v := range s.All() {
+				ranked[i] = append(ranked[i], entry{k, v})
+			}
+		}
+		for rank := 0; ; rank++ {
+			more := false
+			for _, es := range ranked {
+				if rank >= len(es) {
+					continue
+				}
+				more = true
+				if !yield(es[rank].key, es[rank].val) {
+					return
+				}
+			}
+			if !more {
+				return
+			}
+		}
+	}
+}
+
+// Stats суммирует счётчики всех шардов.
+func (c *ShardedCache[K, V]) Stats() Stats {
+	var total Stats
+	for _, s := range c.shards {
+		st := s.Stats()
+		total.Hits += st.Hits
+		total.Misses += st.Misses
+		total.Evictions += st.Evictions
+		total.Expired += st.Expired
+		total.Size += st.Size
+		total.Cost += st.Cost
+	}
+	return total
+}
+
+// DeleteExpired удаляет просроченные записи во всех шардах.
+func (c *ShardedCache[K, V]) DeleteExpired() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.DeleteExpired()
+	}
+	return n
+}
+
+// Close останавливает фоновую очистку во всех шардах.
+func (c *ShardedCache[K, V]) Close() {
+	for _, s := range c.shards {
+		s.Close()
+	}
+}
@@ -0,0 +1,185 @@
+package lru_cache
+
+import (
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShardedCacheBasic(t *testing.T) {
+	t.Parallel()
+
+	c := NewShardedCache[string, int](256, 8, -1)
+	for i := range 32 {
+		c.Put(strconv.Itoa(i), i)
+	}
+	mustEqual(t, c.Size(), 32, "size across shards")
+	mustEqual(t, c.Get("7"), 7, "get")
+	mustEqual(t, c.Get("missing"), -1, "default on miss")
+
+	v, ok := c.Peek("8")
+	mustEqual(t, ok, true, "peek found")
+	mustEqual(t, v, 8, "peek value")
+	mustEqual(t, c.Delete("8"), true, "delete")
+	mustEqual(t, c.Contains("8"), false, "deleted")
+
+	seen := 0
+	for range c.All() {
+		seen++
+	}
+	mustEqual(t, seen, 31, "iteration covers all shards")
+
+	s := c.Stats()
+	mustEqual(t, s.Hits, uint64(1), "hits summed")
+	mustEqual(t, s.Misses, uint64(1), "misses summed")
+
+	c.Purge()
+	mustEqual(t, c.Size(), 0, "purged")
+}
+
+func TestShardedCacheAllInterleavesShardsByRecency(t *testing.T) {
+	t.Parallel()
+
+	const shards = 4
+	c := NewShardedCache[string, int](64, shards, -1)
+	for i := range 64 {
+		c.Put(strconv.Itoa(i), i)
+	}
+
+	// самая свежая запись каждого шарда — наибольший i среди его ключей
+	hottest := map[*LruCache[string, int]]int{}
+	for k, v := range c.All() {
+		if v > hottest[c.shard(k)] {
+			hottest[c.shard(k)] = v
+		}
+	}
+	mustEqual(t, len(hottest), shards, "every shard holds keys")
+
+	seen := map[*LruCache[string, int]]bool{}
+	n := 0
+	for k, v := range c.All() {
+		if n == shards {
+			break
+		}
+		s := c.shard(k)
+		mustEqual(t, seen[s], false, "one entry per shard in the first round")
+		mustEqual(t, v, hottest[s], "first round yields each shard's most recent entry")
+		seen[s] = true
+		n++
+	}
+}
+
+func TestShardedCacheBoundsAndSplitsCost(t *testing.T) {
+	t.Parallel()
+
+	// шардов больше, чем ёмкость, — их число урезается до ёмкости
+	small := NewShardedCache[int, int](2, 16, -1)
+	mustEqual(t, len(small.shards), 2, "shards capped by capacity")
+
+	c := NewShardedCache(100, 4, "",
+		WithMaxCost[int, string](40, func(v string) int64 { return int64(len(v)) }),
+	)
+	for _, s := range c.shards {
+		mustEqual(t, s.capacity, 25, "capacity per shard")
+		mustEqual(t, s.maxCost, int64(10), "max cost per shard")
+	}
+	for i := range 100 {
+		c.Put(i, "xxxx")
+	}
+	mustLE(t, int(c.Stats().Cost), 40, "total cost bounded")
+}
+
+func TestShardedCacheSplitKeepsTotals(t *testing.T) {
+	t.Parallel()
+
+	// 10 на 3 шарда — 4+3+3, а не по 4 (всего 12)
+	c := NewShardedCache(10, 3, "",
+		WithMaxCost[int, string](100, func(v string) int64 { return int64(len(v)) }),
+	)
+	capTotal, costTotal := 0, int64(0)
+	for _, s := range c.shards {
+		capTotal += s.capacity
+		costTotal += s.maxCost
+	}
+	mustEqual(t, capTotal, 10, "total capacity")
+	mustEqual(t, costTotal, int64(100), "total max cost")
+	mustEqual(t, c.MaxEntryCost(), int64(33), "smallest shard budget")
+
+	// запись дороже доли шарда не сохраняется, хотя помещается в общий лимит
+	c.Put(1, strings.Repeat("x", 35))
+	mustEqual(t, c.Contains(1), false, "entry above per-shard budget")
+	c.Put(2, strings.Repeat("x", 33))
+	mustEqual(t, c.Contains(2), true, "entry within every shard budget")
+}
+
+func TestShardedCacheConcurrent(t *testing.T) {
+	capacity := 256
+	c := NewShardedCache[int, int](capacity, 16, -1)
+
+	var wg sync.WaitGroup
+	for g := range runtime.GOMAXPROCS(0) * 4 {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for range 10_000 {
+				k := r.Intn(capacity * 4)
+				if r.Intn(100) < 50 {
+					c.Put(k, k)
+				} else if v := c.Get(k); v != -1 && v != k {
+					t.Errorf("key %d: got %d", k, v)
+				}
+			}
+		}(int64(g + 1))
+	}
+	wg.Wait()
+
+	mustLE(t, c.Size(), capacity, "size must not exceed capacity")
+}
+
+// benchCache — общий набор операций для сравнения реализаций.
+type benchCache interface {
+	Put(key string, val int)
+	Get(key string) int
+}
+
+func benchmarkParallel(b *testing.B, c benchCache, readPercent int) {
+	const keys = 4096
+	names := make([]string, keys)
+	for i := range names {
+		names[i] = "order-" + strconv.Itoa(i)
+		c.Put(names[i], i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			k := names[r.Intn(keys)]
+			if r.Intn(100) < readPercent {
+				_ = c.Get(k)
+			} else {
+				c.Put(k, 1)
+			}
+		}
+	})
+}
+
+func BenchmarkLruCacheParallelRead90(b *testing.B) {
+	benchmarkParallel(b, NewLruCache[string, int](4096, -1), 90)
+}
+
+func BenchmarkShardedCacheParallelRead90(b *testing.B) {
+	benchmarkParallel(b, NewShardedCache[string, int](4096, 32, -1), 90)
+}
+
+func BenchmarkLruCacheParallelRead50(b *testing.B) {
+	benchmarkParallel(b, NewLruCache[string, int](4096, -1), 50)
+}
+
+func BenchmarkShardedCacheParallelRead50(b *testing.B) {
+	benchmarkParallel(b, NewShardedCache[string, int](4096, 32, -1), 50)
+}
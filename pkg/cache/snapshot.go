@@ -0,0 +1,86 @@
+package lru_cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const snapshotVersion = 1
+
+var (
+	ErrSnapshotStale   = errors.New("lru: snapshot is stale")
+	ErrSnapshotCorrupt = errors.New("lru: snapshot is corrupt")
+)
+
+// Entry — запись снимка кэша.
+type Entry[K comparable, V any] struct {
+	Key   K `json:"k"`
+	Value V `json:"v"`
+}
+
+type snapshotFile[K comparable, V any] struct {
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"created_at"`
+	Entries   []Entry[K, V] `json:"entries"`
+}
+
+// WriteSnapshot сохраняет записи в файл в порядке обхода all (для LruCache.All —
+// от недавно использованных к давним). Файл заменяется атомарно через rename.
+func WriteSnapshot[K comparable, V any](path string, all iter.Seq2[K, V]) (int, error) {
+	snap := snapshotFile[K, V]{Version: snapshotVersion, CreatedAt: time.Now().UTC()}
+	for k, v := range all {
+		snap.Entries = append(snap.Entries, Entry[K, V]{Key: k, Value: v})
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("lru - WriteSnapshot - create temp: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := json.NewEncoder(tmp).Encode(snap); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("lru - WriteSnapshot - encode: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("lru - WriteSnapshot - sync: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("lru - WriteSnapshot - close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("lru - WriteSnapshot - rename: %w", err)
+	}
+
+	return len(snap.Entries), nil
+}
+
+// ReadSnapshot читает снимок, записанный WriteSnapshot. Записи возвращаются
+// в сохранённом порядке. Снимок старше maxAge (если maxAge > 0) — ErrSnapshotStale,
+// нечитаемый или чужой версии — ErrSnapshotCorrupt, отсутствующий — os.ErrNotExist.
+func ReadSnapshot[K comparable, V any](path string, maxAge time.Duration) ([]Entry[K, V], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var snap snapshotFile[K, V]
+	if err := json.NewDecoder(f).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+	if snap.Version != snapshotVersion || snap.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("%w: version %d", ErrSnapshotCorrupt, snap.Version)
+	}
+	if age := time.Since(snap.CreatedAt); maxAge > 0 && age > maxAge {
+		return nil, fmt.Errorf("%w: age %s", ErrSnapshotStale, age.Round(time.Second))
+	}
+
+	return snap.Entries, nil
+}
@@ -0,0 +1,83 @@
+package lru_cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTripKeepsLRUOrder(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	src := NewLruCache[string, int](3, -1)
+	src.Put("a", 1)
+	src.Put("b", 2)
+	src.Put("c", 3)
+	_ = src.Get("a") // MRU: a, c, b
+
+	n, err := WriteSnapshot(path, src.All())
+	if err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	mustEqual(t, n, 3, "written entries")
+
+	entries, err := ReadSnapshot[string, int](path, time.Hour)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	mustEqual(t, len(entries), 3, "read entries")
+
+	// восстанавливаем от давних к недавним, чтобы MRU оказался в начале
+	dst := NewLruCache[string, int](3, -1)
+	for i := len(entries) - 1; i >= 0; i-- {
+		dst.Put(entries[i].Key, entries[i].Value)
+	}
+	var keys []string
+	for k := range dst.All() {
+		keys = append(keys, k)
+	}
+	mustEqual(t, len(keys), 3, "restored size")
+	mustEqual(t, keys[0]+keys[1]+keys[2], "acb", "restored order")
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	_, err := ReadSnapshot[string, int](filepath.Join(dir, "missing.json"), 0)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing file: got %v", err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte(`{"version":1,"entries":[`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadSnapshot[string, int](corrupt, 0)
+	if !errors.Is(err, ErrSnapshotCorrupt) {
+		t.Fatalf("corrupt file: got %v", err)
+	}
+
+	wrongVersion := filepath.Join(dir, "v0.json")
+	if err := os.WriteFile(wrongVersion, []byte(`{"version":0,"created_at":"2025-01-01T00:00:00Z"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadSnapshot[string, int](wrongVersion, 0)
+	if !errors.Is(err, ErrSnapshotCorrupt) {
+		t.Fatalf("wrong version: got %v", err)
+	}
+
+	stale := filepath.Join(dir, "stale.json")
+	if err := os.WriteFile(stale, []byte(`{"version":1,"created_at":"2020-01-01T00:00:00Z","entries":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadSnapshot[string, int](stale, time.Hour)
+	if !errors.Is(err, ErrSnapshotStale) {
+		t.Fatalf("stale file: got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package redis
+
+import "time"
+
+// Option -.
+type Option func(*Redis)
+
+// PoolSize -.
+func PoolSize(size int) Option {
+	return func(r *Redis) {
+		r.poolSize = size
+	}
+}
+
+// DialTimeout -.
+func DialTimeout(timeout time.Duration) Option {
+	return func(r *Redis) {
+		r.dialTimeout = timeout
+	}
+}
@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	_defaultPoolSize    = 10
+	_defaultDialTimeout = time.Second
+)
+
+// Redis -.
+type Redis struct {
+	poolSize    int
+	dialTimeout time.Duration
+
+	Client *goredis.Client
+}
+
+// New создаёт клиента по URL вида redis://[:password@]host:port/db.
+// Соединения устанавливаются лениво, поэтому недоступный Redis не мешает старту.
+func New(url string, opts ...Option) (*Redis, error) {
+	r := &Redis{
+		poolSize:    _defaultPoolSize,
+		dialTimeout: _defaultDialTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	clientOpts, err := goredis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("redis - New - goredis.ParseURL: %w", err)
+	}
+	clientOpts.PoolSize = r.poolSize
+	clientOpts.DialTimeout = r.dialTimeout
+
+	r.Client = goredis.NewClient(clientOpts)
+
+	return r, nil
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.Client.Ping(ctx).Err()
+}
+
+func (r *Redis) Close() error {
+	if r.Client != nil {
+		return r.Client.Close()
+	}
+	return nil
+}